@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"io"
 	"log"
 	"net/http"
@@ -51,22 +52,65 @@ func main() {
 	}
 	defer db.Close()
 
+	retryBase := service.DefaultWebhookRetryBase
+	if value := os.Getenv("WEBHOOK_RETRY_BASE"); value != "" {
+		if retryBase, err = time.ParseDuration(value); err != nil || retryBase <= 0 {
+			log.Printf("invalid WEBHOOK_RETRY_BASE: %s", value)
+			return
+		}
+	}
+
 	repository := repository.NewRepository(db)
-	service := service.NewService(repository)
+	dispatcher := service.NewWebhookDispatcher(repository, retryBase)
+	outbox := service.NewOutboxDispatcher(repository, dispatcher)
+	service := service.NewService(repository, os.Getenv("ADMIN_TOKEN"), outbox, dispatcher)
 	handler := handler.NewHandler(service)
 	server := new(Server)
 
 	log.Println("server started on :8080")
 
+	dispatcherCtx, stopDispatcher := context.WithCancel(context.Background())
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		dispatcher.Run(dispatcherCtx)
+	}()
+
+	outboxCtx, stopOutbox := context.WithCancel(context.Background())
+	outboxDone := make(chan struct{})
+	go func() {
+		defer close(outboxDone)
+		outbox.Run(outboxCtx)
+	}()
+
+	// The dispatchers are stopped on every way out of main, the outbox first, as it wakes
+	// the webhook dispatcher. Events and deliveries left in the database are sent after the restart.
+	defer func() {
+		stopOutbox()
+		<-outboxDone
+		log.Println("outbox dispatcher stopped")
+
+		stopDispatcher()
+		<-dispatcherDone
+		log.Println("webhook dispatcher stopped")
+	}()
+
+	// A failed server is reported back instead of exiting right away, so the dispatchers are still stopped.
+	serverErr := make(chan error, 1)
 	go func() {
-		if err := server.Run(os.Getenv("SERVICE_PORT"), handler.InitRoutes()); err != nil {
-			log.Fatalf("error while running server: %s", err.Error())
+		if err := server.Run(os.Getenv("SERVICE_PORT"), handler.InitRoutes()); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
 		}
 	}()
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	select {
+	case <-quit:
+	case err := <-serverErr:
+		log.Printf("error while running server: %s", err.Error())
+		return
+	}
 	log.Println("shutdown server...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
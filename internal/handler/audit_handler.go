@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/karambo3a/avito_test_task/internal/model"
+)
+
+func (h *Handler) ListAuditEvents(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	query := model.AuditListQuery{
+		Actor:      c.Query("actor"),
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		From:       c.Query("from"),
+		To:         c.Query("to"),
+		Cursor:     c.Query("cursor"),
+		Limit:      c.Query("limit"),
+	}
+
+	page, err := h.service.ListAuditEvents(ctx, query)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeInvalidField:
+				log.Println("handler: invalid field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
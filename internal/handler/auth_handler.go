@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/karambo3a/avito_test_task/internal/model"
+)
+
+// Authenticate resolves the bearer token of the request and stores the caller identity
+// in the request context. Requests without a valid token are rejected.
+func (h *Handler) Authenticate(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	secret, _ := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+	identity, err := h.service.Authenticate(ctx, secret)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeUnauthorized:
+				log.Println("handler: unauthorized")
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"error": err,
+				})
+			default:
+				// The request must not reach the handler without an identity.
+				log.Println("handler: server error")
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+		} else {
+			log.Println("handler: server error")
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.Request = c.Request.WithContext(model.WithIdentity(c.Request.Context(), identity))
+	c.Next()
+}
+
+func (h *Handler) IssueToken(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	var reqBody model.APIToken
+	if err := c.BindJSON(&reqBody); err != nil {
+		log.Printf("BindJSON error: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	issued, err := h.service.IssueToken(ctx, reqBody)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeNotFound:
+				log.Println("handler: user not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			case model.CodeInvalidField:
+				log.Println("handler: invalid field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("token issued: %s", issued.Token.TokenID)
+	c.JSON(http.StatusCreated, issued)
+}
+
+func (h *Handler) RevokeToken(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	var req struct {
+		TokenID string `json:"token_id"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		log.Printf("BindJSON error: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	token, err := h.service.RevokeToken(ctx, req.TokenID)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeNotFound:
+				log.Println("handler: token not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("token revoked: %s", token.TokenID)
+	c.JSON(http.StatusOK, gin.H{
+		"token": token,
+	})
+}
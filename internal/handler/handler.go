@@ -15,17 +15,34 @@ func NewHandler(s *service.Service) *Handler {
 
 func (h *Handler) InitRoutes() *gin.Engine {
 	router := gin.Default()
+	router.Use(h.Authenticate)
+
+	authGroup := router.Group("/auth")
+	{
+		authGroup.POST("/issueToken", h.IssueToken)
+		authGroup.POST("/revokeToken", h.RevokeToken)
+	}
 
 	teamGroup := router.Group("/team")
 	{
 		teamGroup.POST("/add", h.AddTeam)
 		teamGroup.GET("/get", h.GetTeam)
+		teamGroup.POST("/setSettings", h.UpdateTeamSettings)
+		teamGroup.POST("/setCodeOwners", h.SetCodeOwners)
+		teamGroup.GET("/getCodeOwners", h.GetCodeOwners)
+		teamGroup.POST("/addMembers", h.AddMembers)
+		teamGroup.POST("/removeMember", h.RemoveMember)
+		teamGroup.POST("/rename", h.RenameTeam)
+		teamGroup.POST("/delete", h.DeleteTeam)
+		teamGroup.POST("/deactivateUsers", h.DeactivateUsers)
 	}
 
 	usersGroup := router.Group("/users")
 	{
 		usersGroup.POST("/setIsActive", h.SetUserIsActive)
 		usersGroup.GET("/getReview", h.GetUserReview)
+		usersGroup.POST("/moveTeam", h.MoveUser)
+		usersGroup.POST("/addAbsence", h.AddAbsence)
 	}
 
 	prGroup := router.Group("/pullRequest")
@@ -33,6 +50,13 @@ func (h *Handler) InitRoutes() *gin.Engine {
 		prGroup.POST("/create", h.CreatePR)
 		prGroup.POST("/merge", h.MergePR)
 		prGroup.POST("/reassign", h.ReassignPR)
+		prGroup.POST("/review", h.SubmitReview)
+		prGroup.POST("/markReady", h.MarkReady)
+		prGroup.POST("/close", h.ClosePR)
+		prGroup.POST("/reopen", h.ReopenPR)
+		prGroup.GET("/list", h.ListPRs)
+		prGroup.GET("/get", h.GetPR)
+		prGroup.GET("/history", h.GetPRHistory)
 	}
 
 	statsGroup := router.Group("/statistics")
@@ -41,5 +65,19 @@ func (h *Handler) InitRoutes() *gin.Engine {
 		statsGroup.GET("/team", h.GetTeamStatistics)
 	}
 
+	auditGroup := router.Group("/audit")
+	{
+		auditGroup.GET("/events", h.ListAuditEvents)
+	}
+
+	webhookGroup := router.Group("/webhooks")
+	{
+		webhookGroup.POST("/subscribe", h.Subscribe)
+		webhookGroup.GET("/list", h.ListSubscriptions)
+		webhookGroup.POST("/unsubscribe", h.Unsubscribe)
+		webhookGroup.GET("/deliveries", h.ListDeliveries)
+		webhookGroup.POST("/replay", h.ReplayDelivery)
+	}
+
 	return router
 }
@@ -14,11 +14,7 @@ import (
 func (h *Handler) CreatePR(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
-	var request struct {
-		PullRequestID   string `json:"pull_request_id"`
-		PullRequestName string `json:"pull_request_name"`
-		AuthorID        string `json:"author_id"`
-	}
+	var request model.CreatePRInput
 
 	if err := c.BindJSON(&request); err != nil {
 		log.Printf("BindJSON error: %v", err)
@@ -26,7 +22,7 @@ func (h *Handler) CreatePR(c *gin.Context) {
 		return
 	}
 
-	pr, err := h.service.CreatePR(ctx, request.PullRequestID, request.PullRequestName, request.AuthorID)
+	pr, err := h.service.CreatePR(ctx, request)
 	if err != nil {
 		var prError *model.PRError
 		if errors.As(err, &prError) {
@@ -46,6 +42,11 @@ func (h *Handler) CreatePR(c *gin.Context) {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"error": err,
 				})
+			case model.CodeInvalidField:
+				log.Println("handler: invalid field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
 			}
 		} else {
 			log.Println("handler: server error")
@@ -59,8 +60,11 @@ func (h *Handler) CreatePR(c *gin.Context) {
 			"pull_request_id":    pr.PullRequestID,
 			"pull_request_name":  pr.PullRequestName,
 			"author_id":          pr.AuthorID,
+			"team_name":          pr.TeamName,
 			"status":             pr.Status,
 			"assigned_reviewers": pr.AssignedReviewers,
+			"createdAt":          pr.CreatedAt,
+			"understaffed":       pr.Understaffed,
 		},
 	})
 }
@@ -70,6 +74,7 @@ func (h *Handler) MergePR(c *gin.Context) {
 	defer cancel()
 	var req struct {
 		PullRequestID string `json:"pull_request_id"`
+		Force         bool   `json:"force"`
 	}
 	if err := c.BindJSON(&req); err != nil {
 		log.Printf("BindJSON error: %v", err)
@@ -77,16 +82,31 @@ func (h *Handler) MergePR(c *gin.Context) {
 		return
 	}
 
-	pr, err := h.service.MergePR(ctx, req.PullRequestID)
+	pr, err := h.service.MergePR(ctx, req.PullRequestID, req.Force)
 	if err != nil {
 		var prError *model.PRError
 		if errors.As(err, &prError) {
 			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
 			case model.CodeNotFound:
 				log.Println("handler: pr not found")
 				c.JSON(http.StatusNotFound, gin.H{
 					"error": err,
 				})
+			case model.CodeNotApproved:
+				log.Println("handler: pr not approved")
+				c.JSON(http.StatusConflict, gin.H{
+					"error": err,
+				})
+			case model.CodeInvalidTransition:
+				log.Println("handler: invalid pr status transition")
+				c.JSON(http.StatusConflict, gin.H{
+					"error": err,
+				})
 			case model.CodeEmptyField:
 				log.Println("handler: empty field")
 				c.JSON(http.StatusBadRequest, gin.H{
@@ -107,7 +127,10 @@ func (h *Handler) MergePR(c *gin.Context) {
 			"author_id":          pr.AuthorID,
 			"status":             pr.Status,
 			"assigned_reviewers": pr.AssignedReviewers,
+			"createdAt":          pr.CreatedAt,
 			"mergedAt":           pr.MergedAt,
+			"force_merged":       pr.ForceMerged,
+			"force_merged_by":    pr.ForceMergedBy,
 		},
 	})
 }
@@ -130,6 +153,11 @@ func (h *Handler) ReassignPR(c *gin.Context) {
 		var prError *model.PRError
 		if errors.As(err, &prError) {
 			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
 			case model.CodeNotFound:
 				log.Println("handler: pr not found")
 				c.JSON(http.StatusNotFound, gin.H{
@@ -140,6 +168,11 @@ func (h *Handler) ReassignPR(c *gin.Context) {
 				c.JSON(http.StatusConflict, gin.H{
 					"error": err,
 				})
+			case model.CodePRNotOpen:
+				log.Println("handler: pr not open")
+				c.JSON(http.StatusConflict, gin.H{
+					"error": err,
+				})
 			case model.CodeNotAssigned:
 				log.Println("handler: user not assigned")
 				c.JSON(http.StatusConflict, gin.H{
@@ -170,7 +203,254 @@ func (h *Handler) ReassignPR(c *gin.Context) {
 			"author_id":          pr.AuthorID,
 			"status":             pr.Status,
 			"assigned_reviewers": pr.AssignedReviewers,
+			"createdAt":          pr.CreatedAt,
 		},
 		"replaced_by": replacedBy,
 	})
 }
+
+func (h *Handler) SubmitReview(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+		ReviewerID    string `json:"reviewer_id"`
+		State         string `json:"state"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		log.Printf("BindJSON error: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	pr, err := h.service.SubmitReview(ctx, req.PullRequestID, req.ReviewerID, req.State)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeNotFound:
+				log.Println("handler: pr not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodePRMerged:
+				log.Println("handler: pr merged")
+				c.JSON(http.StatusConflict, gin.H{
+					"error": err,
+				})
+			case model.CodePRNotOpen:
+				log.Println("handler: pr not open")
+				c.JSON(http.StatusConflict, gin.H{
+					"error": err,
+				})
+			case model.CodeNotAssigned:
+				log.Println("handler: user not assigned")
+				c.JSON(http.StatusConflict, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			case model.CodeInvalidField:
+				log.Println("handler: invalid field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pr": map[string]any{
+			"pull_request_id":    pr.PullRequestID,
+			"pull_request_name":  pr.PullRequestName,
+			"author_id":          pr.AuthorID,
+			"status":             pr.Status,
+			"assigned_reviewers": pr.AssignedReviewers,
+			"createdAt":          pr.CreatedAt,
+		},
+	})
+}
+
+func (h *Handler) GetPR(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	pullRequestID := c.Query("pull_request_id")
+
+	pr, err := h.service.GetPR(ctx, pullRequestID)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeNotFound:
+				log.Println("handler: pr not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pr": pr,
+	})
+}
+
+func (h *Handler) GetPRHistory(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	pullRequestID := c.Query("pull_request_id")
+
+	history, err := h.service.GetPRHistory(ctx, pullRequestID)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeNotFound:
+				log.Println("handler: pr not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func (h *Handler) ListPRs(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	query := model.PRListQuery{
+		Status:      c.Query("status"),
+		AuthorID:    c.Query("author_id"),
+		ReviewerID:  c.Query("reviewer_id"),
+		TeamName:    c.Query("team_name"),
+		CreatedFrom: c.Query("created_from"),
+		CreatedTo:   c.Query("created_to"),
+		MergedFrom:  c.Query("merged_from"),
+		MergedTo:    c.Query("merged_to"),
+		Cursor:      c.Query("cursor"),
+		Limit:       c.Query("limit"),
+	}
+
+	page, err := h.service.ListPRs(ctx, query)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeInvalidField:
+				log.Println("handler: invalid field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *Handler) MarkReady(c *gin.Context) {
+	h.changePRStatus(c, h.service.MarkReady)
+}
+
+func (h *Handler) ClosePR(c *gin.Context) {
+	h.changePRStatus(c, h.service.ClosePR)
+}
+
+func (h *Handler) ReopenPR(c *gin.Context) {
+	h.changePRStatus(c, h.service.ReopenPR)
+}
+
+// changePRStatus handles the lifecycle endpoints that only take a PR id.
+func (h *Handler) changePRStatus(c *gin.Context, change func(ctx context.Context, pullRequestID string) (*model.PullRequest, error)) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		log.Printf("BindJSON error: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	pr, err := change(ctx, req.PullRequestID)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeNotFound:
+				log.Println("handler: pr not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeInvalidTransition:
+				log.Println("handler: invalid pr status transition")
+				c.JSON(http.StatusConflict, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pr": map[string]any{
+			"pull_request_id":    pr.PullRequestID,
+			"pull_request_name":  pr.PullRequestName,
+			"author_id":          pr.AuthorID,
+			"status":             pr.Status,
+			"assigned_reviewers": pr.AssignedReviewers,
+			"createdAt":          pr.CreatedAt,
+			"understaffed":       pr.Understaffed,
+		},
+	})
+}
@@ -28,16 +28,31 @@ func (h *Handler) AddTeam(c *gin.Context) {
 		var prError *model.PRError
 		if errors.As(err, &prError) {
 			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
 			case model.CodeTeamExists:
 				log.Println("handler: team exists")
 				c.JSON(http.StatusBadRequest, gin.H{
 					"error": err,
 				})
+			case model.CodeNotFound:
+				log.Println("handler: fallback or parent team not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
 			case model.CodeEmptyField:
 				log.Println("handler: empty field")
 				c.JSON(http.StatusBadRequest, gin.H{
 					"error": err,
 				})
+			case model.CodeInvalidField:
+				log.Println("handler: invalid field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
 			}
 		} else {
 			log.Println("handler: server error")
@@ -56,8 +71,9 @@ func (h *Handler) GetTeam(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 	teamName := c.Query("team_name")
+	withSubTeams := c.Query("include_subteams") == "true"
 
-	team, err := h.service.GetTeam(ctx, teamName)
+	team, err := h.service.GetTeam(ctx, teamName, withSubTeams)
 	if err != nil {
 		var prError *model.PRError
 		if errors.As(err, &prError) {
@@ -83,3 +99,386 @@ func (h *Handler) GetTeam(c *gin.Context) {
 	log.Printf("team: %+v", team)
 	c.JSON(http.StatusOK, team)
 }
+
+func (h *Handler) UpdateTeamSettings(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	var request model.TeamSettings
+	if err := c.BindJSON(&request); err != nil {
+		log.Printf("BindJSON error: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	settings, err := h.service.UpdateTeamSettings(ctx, request)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeNotFound:
+				log.Println("handler: team not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			case model.CodeInvalidField:
+				log.Println("handler: invalid field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("team settings updated: %+v", settings)
+	c.JSON(http.StatusOK, gin.H{
+		"settings": settings,
+	})
+}
+
+func (h *Handler) SetCodeOwners(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	var request model.CodeOwners
+	if err := c.BindJSON(&request); err != nil {
+		log.Printf("BindJSON error: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	owners, err := h.service.SetCodeOwners(ctx, request)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeNotFound:
+				log.Println("handler: team or owner not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			case model.CodeInvalidField:
+				log.Println("handler: invalid field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("code owners updated: %s", owners.TeamName)
+	c.JSON(http.StatusOK, owners)
+}
+
+func (h *Handler) GetCodeOwners(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	teamName := c.Query("team_name")
+
+	owners, err := h.service.GetCodeOwners(ctx, teamName)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeNotFound:
+				log.Println("handler: team not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, owners)
+}
+
+func (h *Handler) AddMembers(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	var req struct {
+		TeamName string             `json:"team_name"`
+		Members  []model.TeamMember `json:"members"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		log.Printf("BindJSON error: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	team, err := h.service.AddMembers(ctx, req.TeamName, req.Members)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeNotFound:
+				log.Println("handler: team not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			case model.CodeInvalidField:
+				log.Println("handler: invalid field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("team members added: %+v", team)
+	c.JSON(http.StatusOK, gin.H{
+		"team": team,
+	})
+}
+
+func (h *Handler) RemoveMember(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	var req struct {
+		TeamName string `json:"team_name"`
+		UserID   string `json:"user_id"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		log.Printf("BindJSON error: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	handovers, err := h.service.RemoveMember(ctx, req.TeamName, req.UserID)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeNotFound:
+				log.Println("handler: team member not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"team_name":  req.TeamName,
+		"user_id":    req.UserID,
+		"reassigned": handovers,
+	})
+}
+
+func (h *Handler) RenameTeam(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	var req struct {
+		TeamName    string `json:"team_name"`
+		NewTeamName string `json:"new_team_name"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		log.Printf("BindJSON error: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	team, err := h.service.RenameTeam(ctx, req.TeamName, req.NewTeamName)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeNotFound:
+				log.Println("handler: team not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeTeamExists:
+				log.Println("handler: team exists")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			case model.CodeInvalidField:
+				log.Println("handler: invalid field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("team renamed: %+v", team)
+	c.JSON(http.StatusOK, gin.H{
+		"team": team,
+	})
+}
+
+func (h *Handler) DeleteTeam(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	var req struct {
+		TeamName string `json:"team_name"`
+		Force    bool   `json:"force"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		log.Printf("BindJSON error: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	handovers, err := h.service.DeleteTeam(ctx, req.TeamName, req.Force)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeNotFound:
+				log.Println("handler: team not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeTeamHasOpenPRs:
+				log.Println("handler: team has open prs")
+				c.JSON(http.StatusConflict, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"team_name":  req.TeamName,
+		"reassigned": handovers,
+	})
+}
+
+func (h *Handler) DeactivateUsers(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	var req struct {
+		TeamName string   `json:"team_name"`
+		UserIDs  []string `json:"user_ids"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		log.Printf("BindJSON error: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	reassignments, err := h.service.DeactivateUsers(ctx, req.TeamName, req.UserIDs)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeNotFound:
+				log.Println("handler: team or team member not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"team_name":  req.TeamName,
+		"reassigned": reassignments,
+	})
+}
@@ -15,8 +15,9 @@ func (h *Handler) SetUserIsActive(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 	var request struct {
-		UserID   string `json:"user_id"`
-		IsActive bool   `json:"is_active"`
+		UserID          string `json:"user_id"`
+		IsActive        bool   `json:"is_active"`
+		ReassignReviews *bool  `json:"reassign_reviews"`
 	}
 	if err := c.BindJSON(&request); err != nil {
 		log.Printf("BindJSON error: %v", err)
@@ -24,11 +25,17 @@ func (h *Handler) SetUserIsActive(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.SetUserIsActive(ctx, request.UserID, request.IsActive)
+	reassign := request.ReassignReviews == nil || *request.ReassignReviews
+	user, handovers, err := h.service.SetUserIsActive(ctx, request.UserID, request.IsActive, reassign)
 	if err != nil {
 		var prError *model.PRError
 		if errors.As(err, &prError) {
 			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
 			case model.CodeNotFound:
 				log.Println("handler: user not found")
 				c.JSON(http.StatusNotFound, gin.H{
@@ -49,7 +56,8 @@ func (h *Handler) SetUserIsActive(c *gin.Context) {
 
 	log.Printf("user status updates: %s", request.UserID)
 	c.JSON(http.StatusOK, gin.H{
-		"user": user,
+		"user":       user,
+		"reassigned": handovers,
 	})
 }
 
@@ -87,3 +95,106 @@ func (h *Handler) GetUserReview(c *gin.Context) {
 		"pull_requests": pullRequests,
 	})
 }
+
+func (h *Handler) MoveUser(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	var request struct {
+		UserID          string `json:"user_id"`
+		FromTeamName    string `json:"from_team_name"`
+		TeamName        string `json:"team_name"`
+		ReassignReviews bool   `json:"reassign_reviews"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		log.Printf("BindJSON error: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	user, handovers, err := h.service.MoveUser(ctx, request.UserID, request.FromTeamName, request.TeamName, request.ReassignReviews)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeNotFound:
+				log.Println("handler: user or team not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("user moved: %s", request.UserID)
+	c.JSON(http.StatusOK, gin.H{
+		"user":       user,
+		"reassigned": handovers,
+	})
+}
+
+func (h *Handler) AddAbsence(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	var request struct {
+		UserID string `json:"user_id"`
+		From   string `json:"from"`
+		To     string `json:"to"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		log.Printf("BindJSON error: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	absence, err := h.service.AddAbsence(ctx, request.UserID, request.From, request.To)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeNotFound:
+				log.Println("handler: user not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			case model.CodeInvalidField:
+				log.Println("handler: invalid field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("absence added: %+v", absence)
+	c.JSON(http.StatusCreated, gin.H{
+		"absence": absence,
+	})
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/karambo3a/avito_test_task/internal/model"
+)
+
+func (h *Handler) Subscribe(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	var reqBody model.WebhookSubscription
+	if err := c.BindJSON(&reqBody); err != nil {
+		log.Printf("BindJSON error: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	subscription, err := h.service.Subscribe(ctx, reqBody)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			case model.CodeInvalidField:
+				log.Println("handler: invalid field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("webhook subscription created: %s", subscription.SubscriptionID)
+	c.JSON(http.StatusCreated, gin.H{
+		"subscription": subscription,
+	})
+}
+
+func (h *Handler) ListSubscriptions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	subscriptions, err := h.service.ListSubscriptions(ctx)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscriptions": subscriptions,
+	})
+}
+
+func (h *Handler) Unsubscribe(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	var req struct {
+		SubscriptionID string `json:"subscription_id"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		log.Printf("BindJSON error: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	err := h.service.Unsubscribe(ctx, req.SubscriptionID)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeNotFound:
+				log.Println("handler: webhook subscription not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("webhook subscription deleted: %s", req.SubscriptionID)
+	c.JSON(http.StatusOK, gin.H{
+		"subscription_id": req.SubscriptionID,
+	})
+}
+
+func (h *Handler) ListDeliveries(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	deliveries, err := h.service.ListDeliveries(ctx, c.Query("subscription_id"), c.Query("status"), c.Query("limit"))
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeInvalidField:
+				log.Println("handler: invalid field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+	})
+}
+
+func (h *Handler) ReplayDelivery(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	var req struct {
+		DeliveryID int64 `json:"delivery_id"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		log.Printf("BindJSON error: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	delivery, err := h.service.ReplayDelivery(ctx, req.DeliveryID)
+	if err != nil {
+		var prError *model.PRError
+		if errors.As(err, &prError) {
+			switch prError.Code {
+			case model.CodeForbidden:
+				log.Println("handler: forbidden")
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err,
+				})
+			case model.CodeNotFound:
+				log.Println("handler: webhook delivery not found")
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": err,
+				})
+			case model.CodeEmptyField:
+				log.Println("handler: empty field")
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err,
+				})
+			}
+		} else {
+			log.Println("handler: server error")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("webhook delivery replayed: %d", delivery.DeliveryID)
+	c.JSON(http.StatusOK, gin.H{
+		"delivery": delivery,
+	})
+}
@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+const (
+	StrategyRandom      = "random"
+	StrategyLeastLoaded = "least_loaded"
+	StrategyRoundRobin  = "round_robin"
+	StrategyWeighted    = "weighted"
+
+	DefaultReviewersRequired = 2
+)
+
+type ReviewerCandidate struct {
+	UserID         string
+	Weight         int
+	OpenReviews    int
+	MaxOpenReviews *int
+	LastAssignedAt time.Time
+}
+
+// AtCapacity reports whether the candidate cannot take another open review.
+func (c ReviewerCandidate) AtCapacity() bool {
+	return c.MaxOpenReviews != nil && c.OpenReviews >= *c.MaxOpenReviews
+}
+
+// ReviewerPool holds code owners of the changed files, candidates of the author's team and,
+// ordered by priority, candidates of its fallback teams used when the home team runs out of reviewers.
+// LeadCandidates is set when the team requires a lead review that no current reviewer provides,
+// one slot is then reserved for a lead.
+type ReviewerPool struct {
+	Strategy           string
+	ReviewersRequired  int
+	LeadCandidates     []ReviewerCandidate
+	OwnerCandidates    []ReviewerCandidate
+	Candidates         []ReviewerCandidate
+	FallbackCandidates [][]ReviewerCandidate
+}
+
+func (p ReviewerPool) IsFallback(userID string) bool {
+	for _, candidates := range [][]ReviewerCandidate{p.LeadCandidates, p.OwnerCandidates, p.Candidates} {
+		for _, candidate := range candidates {
+			if candidate.UserID == userID {
+				return false
+			}
+		}
+	}
+	for _, candidates := range p.FallbackCandidates {
+		for _, candidate := range candidates {
+			if candidate.UserID == userID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReviewerPicker chooses up to count reviewers from the pool. It is supplied by
+// the service layer and called by the repository inside its transaction.
+type ReviewerPicker func(pool ReviewerPool, count int) []string
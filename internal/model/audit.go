@@ -0,0 +1,89 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditEntityTeam        = "team"
+	AuditEntityUser        = "user"
+	AuditEntityPullRequest = "pull_request"
+	AuditEntityToken       = "api_token"
+	AuditEntityWebhook     = "webhook_subscription"
+	AuditEntityDelivery    = "webhook_delivery"
+)
+
+const (
+	AuditTeamCreate          = "team.create"
+	AuditTeamAddMembers      = "team.add_members"
+	AuditTeamRemoveMember    = "team.remove_member"
+	AuditTeamRename          = "team.rename"
+	AuditTeamDelete          = "team.delete"
+	AuditTeamUpdateSettings  = "team.update_settings"
+	AuditTeamSetCodeOwners   = "team.set_code_owners"
+	AuditTeamDeactivateUsers = "team.deactivate_users"
+	AuditUserSetIsActive     = "user.set_is_active"
+	AuditUserMoveTeam        = "user.move_team"
+	AuditUserAddAbsence      = "user.add_absence"
+	AuditPRCreate            = "pr.create"
+	AuditPRMarkReady         = "pr.mark_ready"
+	AuditPRUpdateStatus      = "pr.update_status"
+	AuditPRMerge             = "pr.merge"
+	AuditPRReassign          = "pr.reassign"
+	AuditPRReview            = "pr.review"
+	AuditTokenIssue          = "token.issue"
+	AuditTokenRevoke         = "token.revoke"
+	AuditWebhookSubscribe    = "webhook.subscribe"
+	AuditWebhookUnsubscribe  = "webhook.unsubscribe"
+	AuditWebhookReplay       = "webhook.replay"
+)
+
+// AuditSystemActor is recorded for changes made without an authenticated caller.
+const AuditSystemActor = "system"
+
+// AuditEvent is one recorded change. Before and After hold the state of the entity around the change
+// and are absent when the entity didn't exist before or after it.
+type AuditEvent struct {
+	EventID    int64           `json:"event_id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditListQuery holds the raw query parameters of the audit log listing.
+type AuditListQuery struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   string
+	From       string
+	To         string
+	Cursor     string
+	Limit      string
+}
+
+// AuditCursor points at the last event of a page, the next page starts right after it.
+type AuditCursor struct {
+	EventID int64 `json:"event_id"`
+}
+
+type AuditListFilter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   string
+	From       *time.Time
+	To         *time.Time
+	After      *AuditCursor
+	Limit      int
+}
+
+type AuditListPage struct {
+	Events     []AuditEvent `json:"events"`
+	NextCursor string       `json:"next_cursor"`
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// APIToken describes an issued API token. It belongs either to a user or to a service account.
+// Only the hash of the secret is stored, the secret itself is returned once when the token is issued.
+type APIToken struct {
+	TokenID        string     `json:"token_id"`
+	UserID         string     `json:"user_id,omitempty"`
+	ServiceAccount string     `json:"service_account,omitempty"`
+	IsAdmin        bool       `json:"is_admin"`
+	CreatedAt      time.Time  `json:"created_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IssuedToken is a newly issued token together with its secret.
+type IssuedToken struct {
+	Token  APIToken `json:"token"`
+	Secret string   `json:"secret"`
+}
+
+// Identity is the authenticated caller of a request. IsAdmin is set only for admin tokens,
+// the admin role in a team gives the rights of its lead in that team alone.
+type Identity struct {
+	TokenID        string
+	UserID         string
+	ServiceAccount string
+	IsAdmin        bool
+}
+
+type identityKey struct{}
+
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the caller identity stored by the authentication middleware.
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
+
+// Actor names the caller in the audit log: the user ID for user tokens,
+// "service:" followed by the account name for service accounts.
+func (i *Identity) Actor() string {
+	if i.UserID != "" {
+		return i.UserID
+	}
+	return "service:" + i.ServiceAccount
+}
@@ -3,21 +3,36 @@ package model
 import "fmt"
 
 const (
-	CodeTeamExists  = "TEAM_EXISTS"
-	CodePRExists    = "PR_EXISTS"
-	CodePRMerged    = "PR_MERGED"
-	CodeNotAssigned = "NOT_ASSIGNED"
-	CodeNoCandidate = "NO_CANDIDATE"
-	CodeNotFound    = "NOT_FOUND"
-	CodeEmptyField  = "EMPTY_FIELD"
-
-	MsgTeamExists  = "team_name already exists"
-	MsgPRExists    = "PR id already exists"
-	MsgPRMerged    = "cannot reassign on merged PR"
-	MsgNotAssigned = "reviewer is not assigned to this PR"
-	MsgNoCandidate = "no active replacement candidate in team"
-	MsgNotFound    = "resource not found"
-	MsgEmptyField  = "field is empty"
+	CodeTeamExists        = "TEAM_EXISTS"
+	CodePRExists          = "PR_EXISTS"
+	CodePRMerged          = "PR_MERGED"
+	CodeNotAssigned       = "NOT_ASSIGNED"
+	CodeNoCandidate       = "NO_CANDIDATE"
+	CodeNotFound          = "NOT_FOUND"
+	CodeEmptyField        = "EMPTY_FIELD"
+	CodeInvalidField      = "INVALID_FIELD"
+	CodeNotApproved       = "NOT_APPROVED"
+	CodeInvalidTransition = "INVALID_TRANSITION"
+	CodePRNotOpen         = "PR_NOT_OPEN"
+	CodeTeamHasOpenPRs    = "TEAM_HAS_OPEN_PRS"
+	CodeUnauthorized      = "UNAUTHORIZED"
+	CodeForbidden         = "FORBIDDEN"
+
+	MsgTeamExists        = "team_name already exists"
+	MsgPRExists          = "PR id already exists"
+	MsgPRMerged          = "cannot reassign on merged PR"
+	MsgPRMergedReview    = "cannot review merged PR"
+	MsgNotAssigned       = "reviewer is not assigned to this PR"
+	MsgNoCandidate       = "no active replacement candidate in team"
+	MsgNotFound          = "resource not found"
+	MsgEmptyField        = "field is empty"
+	MsgInvalidField      = "field is invalid"
+	MsgNotApproved       = "PR does not have required approvals"
+	MsgInvalidTransition = "cannot change PR status"
+	MsgPRNotOpen         = "PR is not open"
+	MsgTeamHasOpenPRs    = "team still has open PRs"
+	MsgUnauthorized      = "missing or invalid API token"
+	MsgForbidden         = "not enough permissions"
 )
 
 type PRError struct {
@@ -50,6 +65,13 @@ func NewPRMergedsError() *PRError {
 	}
 }
 
+func NewPRMergedReviewError() *PRError {
+	return &PRError{
+		Code:    CodePRMerged,
+		Message: MsgPRMergedReview,
+	}
+}
+
 func NewNotAssignedError() *PRError {
 	return &PRError{
 		Code:    CodeNotAssigned,
@@ -77,3 +99,52 @@ func NewEmptyFieldError(field string) *PRError {
 		Message: fmt.Sprintf("%s %s", field, MsgEmptyField),
 	}
 }
+
+func NewInvalidFieldError(field string) *PRError {
+	return &PRError{
+		Code:    CodeInvalidField,
+		Message: fmt.Sprintf("%s %s", field, MsgInvalidField),
+	}
+}
+
+func NewNotApprovedError() *PRError {
+	return &PRError{
+		Code:    CodeNotApproved,
+		Message: MsgNotApproved,
+	}
+}
+
+func NewInvalidTransitionError(from, to string) *PRError {
+	return &PRError{
+		Code:    CodeInvalidTransition,
+		Message: fmt.Sprintf("%s from %s to %s", MsgInvalidTransition, from, to),
+	}
+}
+
+func NewPRNotOpenError() *PRError {
+	return &PRError{
+		Code:    CodePRNotOpen,
+		Message: MsgPRNotOpen,
+	}
+}
+
+func NewTeamHasOpenPRsError() *PRError {
+	return &PRError{
+		Code:    CodeTeamHasOpenPRs,
+		Message: MsgTeamHasOpenPRs,
+	}
+}
+
+func NewUnauthorizedError() *PRError {
+	return &PRError{
+		Code:    CodeUnauthorized,
+		Message: MsgUnauthorized,
+	}
+}
+
+func NewForbiddenError() *PRError {
+	return &PRError{
+		Code:    CodeForbidden,
+		Message: MsgForbidden,
+	}
+}
@@ -1,29 +1,117 @@
 package model
 
+import "time"
+
+// Roles of a user within a team.
+const (
+	RoleMember = "member"
+	RoleLead   = "lead"
+	RoleAdmin  = "admin"
+)
+
 type TeamMember struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID         string    `json:"user_id"`
+	Username       string    `json:"username"`
+	IsActive       bool      `json:"is_active"`
+	Role           string    `json:"role"`
+	ReviewWeight   int       `json:"review_weight,omitempty"`
+	MaxOpenReviews *int      `json:"max_open_reviews,omitempty"`
+	Absences       []Absence `json:"absences,omitempty"`
+}
+
+// Absence is a window during which the user is not picked as a reviewer.
+type Absence struct {
+	UserID string    `json:"user_id"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
 }
 
 type Team struct {
-	TeamName string       `json:"team_name"`
-	Members  []TeamMember `json:"members"`
+	TeamName           string       `json:"team_name"`
+	AssignmentStrategy string       `json:"assignment_strategy,omitempty"`
+	ReviewersRequired  int          `json:"reviewers_required,omitempty"`
+	ApprovalsRequired  int          `json:"approvals_required"`
+	RequireLeadReview  bool         `json:"require_lead_review"`
+	FallbackTeams      []string     `json:"fallback_teams,omitempty"`
+	ParentTeamName     string       `json:"parent_team_name,omitempty"`
+	Members            []TeamMember `json:"members"`
+	SubTeams           []Team       `json:"sub_teams,omitempty"`
+}
+
+type TeamSettings struct {
+	TeamName           string   `json:"team_name"`
+	AssignmentStrategy string   `json:"assignment_strategy"`
+	ReviewersRequired  int      `json:"reviewers_required"`
+	ApprovalsRequired  *int     `json:"approvals_required"`
+	RequireLeadReview  *bool    `json:"require_lead_review"`
+	FallbackTeams      []string `json:"fallback_teams"`
+	// ParentTeamName is left unchanged when nil and cleared when empty.
+	ParentTeamName *string `json:"parent_team_name"`
 }
 
+// User is a user with the teams they belong to. TeamName is set only when the user
+// is a member of exactly one team.
 type User struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID   string   `json:"user_id"`
+	Username string   `json:"username"`
+	TeamName string   `json:"team_name"`
+	Teams    []string `json:"teams"`
+	IsActive bool     `json:"is_active"`
+}
+
+type CodeOwnerRule struct {
+	Pattern string   `json:"pattern"`
+	Users   []string `json:"users"`
+	Teams   []string `json:"teams"`
+}
+
+type CodeOwners struct {
+	TeamName string          `json:"team_name"`
+	Rules    []CodeOwnerRule `json:"rules"`
+}
+
+type CreatePRInput struct {
+	PullRequestID   string   `json:"pull_request_id"`
+	PullRequestName string   `json:"pull_request_name"`
+	AuthorID        string   `json:"author_id"`
+	TeamName        string   `json:"team_name"`
+	ChangedFiles    []string `json:"changed_files"`
+	Draft           bool     `json:"draft"`
+
+	OwnerIDs   []string `json:"-"`
+	OwnerTeams []string `json:"-"`
 }
 
 type PullRequest struct {
 	PullRequestShort
 
-	AssignedReviewers []string `json:"assigned_reviewers"`
-	CreatedAt         string   `json:"createdAt"`
-	MergedAt          string   `json:"mergedAt"`
+	TeamName          string     `json:"team_name,omitempty"`
+	AssignedReviewers []Reviewer `json:"assigned_reviewers"`
+	CreatedAt         string     `json:"createdAt"`
+	MergedAt          string     `json:"mergedAt"`
+	ForceMerged       bool       `json:"force_merged"`
+	ForceMergedBy     string     `json:"force_merged_by,omitempty"`
+	Understaffed      bool       `json:"understaffed,omitempty"`
+}
+
+const (
+	PRStatusDraft  = "DRAFT"
+	PRStatusOpen   = "OPEN"
+	PRStatusMerged = "MERGED"
+	PRStatusClosed = "CLOSED"
+)
+
+const (
+	ReviewStatePending          = "PENDING"
+	ReviewStateApproved         = "APPROVED"
+	ReviewStateChangesRequested = "CHANGES_REQUESTED"
+	ReviewStateCommented        = "COMMENTED"
+)
+
+type Reviewer struct {
+	UserID     string `json:"user_id"`
+	State      string `json:"state"`
+	IsFallback bool   `json:"is_fallback"`
 }
 
 type PullRequestShort struct {
@@ -32,3 +120,114 @@ type PullRequestShort struct {
 	AuthorID        string `json:"author_id"`
 	Status          string `json:"status"`
 }
+
+const (
+	HandoverReassigned          = "reassigned"
+	HandoverPartiallyReassigned = "partially_reassigned"
+	HandoverRemoved             = "removed"
+	HandoverNoCandidate         = "no_candidate"
+)
+
+// ReviewHandover describes an OPEN review taken from a user who left the team or became unavailable.
+// NewReviewerID is empty if the review was removed because the PR had enough reviewers
+// or because nobody could take it over.
+type ReviewHandover struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	NewReviewerID string `json:"new_reviewer_id"`
+	Outcome       string `json:"outcome"`
+}
+
+// PRReassignment is the outcome of a bulk deactivation for one PR.
+type PRReassignment struct {
+	PullRequestID    string   `json:"pull_request_id"`
+	RemovedReviewers []string `json:"removed_reviewers"`
+	NewReviewers     []string `json:"new_reviewers"`
+	Outcome          string   `json:"outcome"`
+}
+
+// Reasons a reviewer was assigned to or unassigned from a PR. Membership covers leaving the PR's team.
+const (
+	AssignmentReasonInitial      = "initial"
+	AssignmentReasonReassign     = "reassign"
+	AssignmentReasonDeactivation = "deactivation"
+	AssignmentReasonMembership   = "membership"
+)
+
+// ReviewerAssignment is a period during which the user was a reviewer of the PR.
+// UnassignedAt is nil while the user is still a reviewer.
+type ReviewerAssignment struct {
+	UserID         string     `json:"user_id"`
+	AssignedAt     time.Time  `json:"assigned_at"`
+	Reason         string     `json:"reason"`
+	UnassignedAt   *time.Time `json:"unassigned_at,omitempty"`
+	UnassignReason string     `json:"unassign_reason,omitempty"`
+}
+
+const (
+	PRHistoryStatusChanged      = "status_changed"
+	PRHistoryReviewerAssigned   = "reviewer_assigned"
+	PRHistoryReviewerUnassigned = "reviewer_unassigned"
+)
+
+// PRHistoryEvent is an entry of the PR timeline. Status changes have FromStatus (empty when the PR
+// was created) and ToStatus, reviewer changes have ReviewerID and Reason.
+type PRHistoryEvent struct {
+	Type       string    `json:"type"`
+	At         time.Time `json:"at"`
+	FromStatus string    `json:"from_status,omitempty"`
+	ToStatus   string    `json:"to_status,omitempty"`
+	ReviewerID string    `json:"reviewer_id,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+type PRHistory struct {
+	PullRequestID string               `json:"pull_request_id"`
+	Assignments   []ReviewerAssignment `json:"assignments"`
+	Timeline      []PRHistoryEvent     `json:"timeline"`
+}
+
+type PullRequestSummary struct {
+	PullRequestShort
+
+	CreatedAt string `json:"createdAt"`
+	MergedAt  string `json:"mergedAt,omitempty"`
+}
+
+// PRListQuery holds the raw query parameters of the PR listing.
+type PRListQuery struct {
+	Status      string
+	AuthorID    string
+	ReviewerID  string
+	TeamName    string
+	CreatedFrom string
+	CreatedTo   string
+	MergedFrom  string
+	MergedTo    string
+	Cursor      string
+	Limit       string
+}
+
+// PRCursor points at the last PR of a page, the next page starts right after it.
+type PRCursor struct {
+	CreatedAt     time.Time `json:"created_at"`
+	PullRequestID string    `json:"pull_request_id"`
+}
+
+type PRListFilter struct {
+	Status      string
+	AuthorID    string
+	ReviewerID  string
+	TeamName    string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MergedFrom  *time.Time
+	MergedTo    *time.Time
+	After       *PRCursor
+	Limit       int
+}
+
+type PRListPage struct {
+	PullRequests []PullRequestSummary `json:"pull_requests"`
+	NextCursor   string               `json:"next_cursor"`
+}
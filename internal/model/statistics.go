@@ -1,16 +1,19 @@
 package model
 
 type UserStatistics struct {
-	UserID               string `json:"user_id"`
-	Username             string `json:"username"`
-	TeamName             string `json:"team_name"`
-	AssignedReviewsCount int    `json:"assigned_reviews_count"`
-	AuthoredPRsCount     int    `json:"authored_prs_count"`
+	UserID               string   `json:"user_id"`
+	Username             string   `json:"username"`
+	TeamName             string   `json:"team_name"`
+	Teams                []string `json:"teams"`
+	AssignedReviewsCount int      `json:"assigned_reviews_count"`
+	AuthoredPRsCount     int      `json:"authored_prs_count"`
 }
 
+// TeamStatistics aggregates PRs of the team and all of its sub-teams.
 type TeamStatistics struct {
-	TeamName  string `json:"team_name"`
-	TotalPRs  int    `json:"total_prs"`
-	MergedPRs int    `json:"merged_prs"`
-	OpenPRs   int    `json:"open_prs"`
+	TeamName  string   `json:"team_name"`
+	SubTeams  []string `json:"sub_teams"`
+	TotalPRs  int      `json:"total_prs"`
+	MergedPRs int      `json:"merged_prs"`
+	OpenPRs   int      `json:"open_prs"`
 }
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Event types subscribers can receive.
+const (
+	WebhookEventReviewersAssigned   = "pr.reviewers_assigned"
+	WebhookEventReviewerReassigned  = "pr.reviewer_reassigned"
+	WebhookEventPRMerged            = "pr.merged"
+	WebhookEventUserActivityChanged = "user.activity_changed"
+	WebhookEventTeamMembersChanged  = "team.members_changed"
+)
+
+var WebhookEventTypes = []string{
+	WebhookEventReviewersAssigned,
+	WebhookEventReviewerReassigned,
+	WebhookEventPRMerged,
+	WebhookEventUserActivityChanged,
+	WebhookEventTeamMembersChanged,
+}
+
+// Headers of webhook requests. The signature is "sha256=" followed by the hex HMAC-SHA256
+// of the body keyed with the subscription secret.
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed"
+)
+
+// WebhookSubscription is an endpoint receiving events of the given types. The secret signs
+// the payloads and is returned only when the subscription is created.
+type WebhookSubscription struct {
+	SubscriptionID string    `json:"subscription_id"`
+	URL            string    `json:"url"`
+	Secret         string    `json:"secret,omitempty"`
+	EventTypes     []string  `json:"event_types"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// WebhookEvent is the JSON body posted to subscribers.
+type WebhookEvent struct {
+	EventID    string          `json:"event_id"`
+	EventType  string          `json:"event_type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// ReviewerReassignment is the data of pr.reviewer_reassigned events.
+type ReviewerReassignment struct {
+	PullRequestID    string   `json:"pull_request_id"`
+	RemovedReviewers []string `json:"removed_reviewers"`
+	NewReviewers     []string `json:"new_reviewers"`
+	Reason           string   `json:"reason"`
+}
+
+// UserActivityChange is the data of user.activity_changed events.
+type UserActivityChange struct {
+	UserID   string `json:"user_id"`
+	IsActive bool   `json:"is_active"`
+}
+
+// TeamMembersChange is the data of team.members_changed events.
+type TeamMembersChange struct {
+	TeamName       string   `json:"team_name"`
+	AddedUserIDs   []string `json:"added_user_ids"`
+	RemovedUserIDs []string `json:"removed_user_ids"`
+}
+
+// WebhookDelivery is an event queued for one subscription. AttemptCount counts the attempts since
+// the delivery was queued or last replayed, Attempts keeps all of them.
+type WebhookDelivery struct {
+	DeliveryID     int64            `json:"delivery_id"`
+	SubscriptionID string           `json:"subscription_id"`
+	EventID        string           `json:"event_id"`
+	EventType      string           `json:"event_type"`
+	Status         string           `json:"status"`
+	AttemptCount   int              `json:"attempt_count"`
+	NextAttemptAt  *time.Time       `json:"next_attempt_at,omitempty"`
+	LastError      string           `json:"last_error,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	DeliveredAt    *time.Time       `json:"delivered_at,omitempty"`
+	Attempts       []WebhookAttempt `json:"attempts"`
+}
+
+// WebhookAttempt is one try to post a delivery. StatusCode is zero when no response was received.
+type WebhookAttempt struct {
+	AttemptedAt time.Time `json:"attempted_at"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMS  int64     `json:"duration_ms"`
+}
+
+// OutboxEvent is an event written together with the change it describes and not yet queued for the subscribers.
+type OutboxEvent struct {
+	OutboxID  int64
+	EventType string
+	Data      json.RawMessage
+	CreatedAt time.Time
+}
+
+// EventID is the ID the event is sent with, it's the same for every subscriber and attempt.
+func (e OutboxEvent) EventID() string {
+	return strconv.FormatInt(e.OutboxID, 10)
+}
+
+// OutboxEncoder builds the request body of the event.
+type OutboxEncoder func(event OutboxEvent) ([]byte, error)
+
+// WebhookDispatch is a delivery claimed by the dispatcher together with what is needed to send it.
+type WebhookDispatch struct {
+	DeliveryID   int64
+	EventType    string
+	URL          string
+	Secret       string
+	Payload      []byte
+	AttemptCount int
+}
+
+type WebhookDeliveryFilter struct {
+	SubscriptionID string
+	Status         string
+	Limit          int
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/karambo3a/avito_test_task/internal/model"
+)
+
+type AuditPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewAuditPostgresRepository(db *sql.DB) *AuditPostgresRepository {
+	return &AuditPostgresRepository{db: db}
+}
+
+// ListAuditEvents returns a page of events ordered from the newest to the oldest and the cursor of the next page, if any.
+func (r *AuditPostgresRepository) ListAuditEvents(ctx context.Context, filter model.AuditListFilter) ([]model.AuditEvent, *model.AuditCursor, error) {
+	conditions := []string{"TRUE"}
+	args := []any{}
+	addCondition := func(condition string, values ...any) {
+		placeholders := make([]any, 0, len(values))
+		for _, value := range values {
+			args = append(args, value)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+		conditions = append(conditions, fmt.Sprintf(condition, placeholders...))
+	}
+
+	if filter.Actor != "" {
+		addCondition("actor = %s", filter.Actor)
+	}
+	if filter.Action != "" {
+		addCondition("action = %s", filter.Action)
+	}
+	if filter.EntityType != "" {
+		addCondition("entity_type = %s", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		addCondition("entity_id = %s", filter.EntityID)
+	}
+	if filter.From != nil {
+		addCondition("created_at >= %s", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at < %s", *filter.To)
+	}
+	if filter.After != nil {
+		addCondition("event_id < %s", filter.After.EventID)
+	}
+	args = append(args, filter.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT event_id, actor, action, entity_type, entity_id, before, after, created_at
+		FROM audit_event
+		WHERE %s
+		ORDER BY event_id DESC
+		LIMIT $%d
+		`, strings.Join(conditions, " AND "), len(args)), args...)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	events := []model.AuditEvent{}
+	var next *model.AuditCursor
+	for rows.Next() {
+		if len(events) == filter.Limit {
+			next = &model.AuditCursor{EventID: events[len(events)-1].EventID}
+			break
+		}
+
+		var event model.AuditEvent
+		var before, after []byte
+		if err = rows.Scan(&event.EventID, &event.Actor, &event.Action, &event.EntityType, &event.EntityID, &before, &after, &event.CreatedAt); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, nil, fmt.Errorf("scan error: %w", err)
+		}
+		event.Before, event.After = before, after
+
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return events, next, nil
+}
+
+// auditEntry is a change to be recorded in the audit log. Before and after are marshaled to JSON,
+// nil means the entity didn't exist.
+type auditEntry struct {
+	action     string
+	entityType string
+	entityID   string
+	before     any
+	after      any
+}
+
+// reviewersSnapshot is the audited state of a PR whose reviewers are changed.
+type reviewersSnapshot struct {
+	Reviewers []string `json:"reviewers"`
+}
+
+// writeAuditEvents appends the entries to the audit log inside the transaction of the change,
+// so an event is recorded if and only if the change is committed. The actor is the caller
+// stored in the context.
+func writeAuditEvents(ctx context.Context, tx *sql.Tx, entries ...auditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	actor := model.AuditSystemActor
+	if identity, ok := model.IdentityFromContext(ctx); ok {
+		actor = identity.Actor()
+	}
+
+	actions := make([]string, 0, len(entries))
+	entityTypes := make([]string, 0, len(entries))
+	entityIDs := make([]string, 0, len(entries))
+	befores := make([]string, 0, len(entries))
+	afters := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		before, err := marshalAuditState(entry.before)
+		if err != nil {
+			return err
+		}
+		after, err := marshalAuditState(entry.after)
+		if err != nil {
+			return err
+		}
+
+		actions = append(actions, entry.action)
+		entityTypes = append(entityTypes, entry.entityType)
+		entityIDs = append(entityIDs, entry.entityID)
+		befores = append(befores, before)
+		afters = append(afters, after)
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO audit_event (actor, action, entity_type, entity_id, before, after)
+		SELECT $1, e.action, e.entity_type, e.entity_id, NULLIF(e.before, '')::JSONB, NULLIF(e.after, '')::JSONB
+		FROM UNNEST($2::VARCHAR[], $3::VARCHAR[], $4::VARCHAR[], $5::TEXT[], $6::TEXT[])
+			WITH ORDINALITY AS e(action, entity_type, entity_id, before, after, position)
+		ORDER BY e.position
+		`, actor, actions, entityTypes, entityIDs, befores, afters)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return fmt.Errorf("exec error: %w", err)
+	}
+
+	return nil
+}
+
+// marshalAuditState encodes the state of an audited entity, an empty result stands for no state.
+func marshalAuditState(state any) (string, error) {
+	if state == nil {
+		return "", nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		log.Printf("marshal error: %v", err)
+		return "", fmt.Errorf("marshal error: %w", err)
+	}
+	if string(data) == "null" {
+		return "", nil
+	}
+	return string(data), nil
+}
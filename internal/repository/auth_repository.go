@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/karambo3a/avito_test_task/internal/model"
+)
+
+type AuthPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewAuthPostgresRepository(db *sql.DB) *AuthPostgresRepository {
+	return &AuthPostgresRepository{db: db}
+}
+
+// CreateToken stores the token with the hash of its secret.
+func (r *AuthPostgresRepository) CreateToken(ctx context.Context, token model.APIToken, tokenHash string) (*model.APIToken, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	if token.UserID != "" {
+		var exists bool
+		err = tx.QueryRowContext(ctx, `
+			SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)
+			`, token.UserID).Scan(&exists)
+		if err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		if !exists {
+			log.Printf("user not found: %s", token.UserID)
+			return nil, model.NewNotFoundError()
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO api_token
+		(token_id, token_hash, user_id, service_account, is_admin)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5)
+		RETURNING created_at
+		`, token.TokenID, tokenHash, token.UserID, token.ServiceAccount, token.IsAdmin).Scan(&token.CreatedAt)
+	if err != nil {
+		log.Printf("insert error: %v", err)
+		return nil, fmt.Errorf("insert error: %w", err)
+	}
+
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditTokenIssue,
+		entityType: model.AuditEntityToken,
+		entityID:   token.TokenID,
+		after:      token,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return &token, nil
+}
+
+// RevokeToken marks the token as revoked. Revoking an already revoked token keeps the original time.
+func (r *AuthPostgresRepository) RevokeToken(ctx context.Context, tokenID string) (*model.APIToken, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	before, err := r.GetToken(ctx, tx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if before.RevokedAt != nil {
+		if err = tx.Commit(); err != nil {
+			log.Printf("commit transaction error: %v", err)
+			return nil, fmt.Errorf("commit transaction error: %w", err)
+		}
+		return before, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE api_token
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE token_id = $1
+		`, tokenID)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return nil, fmt.Errorf("exec error: %w", err)
+	}
+
+	token, err := r.GetToken(ctx, tx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditTokenRevoke,
+		entityType: model.AuditEntityToken,
+		entityID:   tokenID,
+		before:     before,
+		after:      token,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return token, nil
+}
+
+// GetToken reads the token and locks it until the end of the transaction.
+func (r *AuthPostgresRepository) GetToken(ctx context.Context, tx *sql.Tx, tokenID string) (*model.APIToken, error) {
+	var token model.APIToken
+	var userID, serviceAccount sql.NullString
+	var revokedAt sql.NullTime
+	err := tx.QueryRowContext(ctx, `
+		SELECT token_id, user_id, service_account, is_admin, created_at, revoked_at
+		FROM api_token
+		WHERE token_id = $1
+		FOR UPDATE
+		`, tokenID).Scan(&token.TokenID, &userID, &serviceAccount, &token.IsAdmin, &token.CreatedAt, &revokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("token not found: %s", tokenID)
+			return nil, model.NewNotFoundError()
+		}
+		log.Printf("scan error: %v", err)
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	token.UserID, token.ServiceAccount = userID.String, serviceAccount.String
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return &token, nil
+}
+
+// GetIdentity resolves the caller by the hash of a token secret. Revoked and unknown tokens
+// are rejected with UNAUTHORIZED.
+func (r *AuthPostgresRepository) GetIdentity(ctx context.Context, tokenHash string) (*model.Identity, error) {
+	var identity model.Identity
+	var userID, serviceAccount sql.NullString
+	err := r.db.QueryRowContext(ctx, `
+		SELECT t.token_id, t.user_id, t.service_account, t.is_admin
+		FROM api_token AS t
+		WHERE t.token_hash = $1 AND t.revoked_at IS NULL
+		`, tokenHash).Scan(&identity.TokenID, &userID, &serviceAccount, &identity.IsAdmin)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Println("token not found or revoked")
+			return nil, model.NewUnauthorizedError()
+		}
+		log.Printf("scan error: %v", err)
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	identity.UserID, identity.ServiceAccount = userID.String, serviceAccount.String
+	return &identity, nil
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/karambo3a/avito_test_task/internal/model"
+)
+
+type OutboxPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxPostgresRepository(db *sql.DB) *OutboxPostgresRepository {
+	return &OutboxPostgresRepository{db: db}
+}
+
+// DrainOutbox takes up to limit unpublished events in the order they were written, queues their webhook
+// deliveries and marks them as published, all in one transaction. If it fails the events stay unpublished
+// and are taken again, deliveries already queued for an event are not duplicated. It returns the number
+// of published events and of queued deliveries.
+func (r *OutboxPostgresRepository) DrainOutbox(ctx context.Context, limit int, encode model.OutboxEncoder) (int, int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return 0, 0, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT outbox_id, event_type, data::TEXT, created_at
+		FROM outbox_event
+		WHERE published_at IS NULL
+		ORDER BY outbox_id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+		`, limit)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return 0, 0, fmt.Errorf("query error: %w", err)
+	}
+
+	events := []model.OutboxEvent{}
+	for rows.Next() {
+		var event model.OutboxEvent
+		var data string
+		if err = rows.Scan(&event.OutboxID, &event.EventType, &data, &event.CreatedAt); err != nil {
+			rows.Close()
+			log.Printf("scan error: %v", err)
+			return 0, 0, fmt.Errorf("scan error: %w", err)
+		}
+		event.Data = json.RawMessage(data)
+		events = append(events, event)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return 0, 0, fmt.Errorf("rows error: %w", err)
+	}
+	if len(events) == 0 {
+		return 0, 0, nil
+	}
+
+	var deliveries int64
+	outboxIDs := make([]int64, 0, len(events))
+	for _, event := range events {
+		payload, err := encode(event)
+		if err != nil {
+			log.Printf("encode outbox event %d error: %v", event.OutboxID, err)
+			return 0, 0, fmt.Errorf("encode outbox event error: %w", err)
+		}
+
+		count, err := enqueueDeliveries(ctx, tx, event.EventID(), event.EventType, payload)
+		if err != nil {
+			return 0, 0, err
+		}
+		deliveries += count
+		outboxIDs = append(outboxIDs, event.OutboxID)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE outbox_event
+		SET published_at = CURRENT_TIMESTAMP
+		WHERE outbox_id = ANY($1)
+		`, outboxIDs)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return 0, 0, fmt.Errorf("exec error: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return 0, 0, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return len(events), deliveries, nil
+}
+
+// outboxEntry is an event to be published once the change is committed. Data is marshaled to JSON.
+type outboxEntry struct {
+	eventType string
+	data      any
+}
+
+// writeOutboxEvents stores the entries inside the transaction of the change, so an event is published
+// if and only if the change is committed, even if the service stops right after the commit.
+func writeOutboxEvents(ctx context.Context, tx *sql.Tx, entries ...outboxEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	eventTypes := make([]string, 0, len(entries))
+	data := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		encoded, err := json.Marshal(entry.data)
+		if err != nil {
+			log.Printf("marshal error: %v", err)
+			return fmt.Errorf("marshal error: %w", err)
+		}
+		eventTypes = append(eventTypes, entry.eventType)
+		data = append(data, string(encoded))
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO outbox_event (event_type, data)
+		SELECT e.event_type, e.data::JSONB
+		FROM UNNEST($1::VARCHAR[], $2::TEXT[]) WITH ORDINALITY AS e(event_type, data, position)
+		ORDER BY e.position
+		`, eventTypes, data)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return fmt.Errorf("exec error: %w", err)
+	}
+
+	return nil
+}
+
+// reassignmentEntry is the event of reviewers of the PR being replaced.
+func reassignmentEntry(pullRequestID string, removed, added []string, reason string) outboxEntry {
+	return outboxEntry{
+		eventType: model.WebhookEventReviewerReassigned,
+		data: model.ReviewerReassignment{
+			PullRequestID:    pullRequestID,
+			RemovedReviewers: append([]string{}, removed...),
+			NewReviewers:     append([]string{}, added...),
+			Reason:           reason,
+		},
+	}
+}
+
+// membersEntry is the event of users joining or leaving the team.
+func membersEntry(teamName string, added, removed []string) outboxEntry {
+	return outboxEntry{
+		eventType: model.WebhookEventTeamMembersChanged,
+		data: model.TeamMembersChange{
+			TeamName:       teamName,
+			AddedUserIDs:   append([]string{}, added...),
+			RemovedUserIDs: append([]string{}, removed...),
+		},
+	}
+}
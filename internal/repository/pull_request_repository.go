@@ -1,10 +1,14 @@
 package repository
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"fmt"
 	"log"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/karambo3a/avito_test_task/internal/model"
 )
@@ -17,7 +21,8 @@ func NewPRPostgresRepository(db *sql.DB) *PRPostgresRepository {
 	return &PRPostgresRepository{db: db}
 }
 
-func (r *PRPostgresRepository) CreatePR(ctx context.Context, pullRequestID, pullRequestName, authorID string) (*model.PullRequest, error) {
+func (r *PRPostgresRepository) CreatePR(ctx context.Context, input model.CreatePRInput, pick model.ReviewerPicker) (*model.PullRequest, error) {
+	pullRequestID, pullRequestName, authorID := input.PullRequestID, input.PullRequestName, input.AuthorID
 	ok, err := r.AuthorExists(ctx, authorID)
 	if err != nil {
 		return nil, err
@@ -49,57 +54,77 @@ func (r *PRPostgresRepository) CreatePR(ctx context.Context, pullRequestID, pull
 		return nil, model.NewPRExistsError()
 	}
 
-	reviewers, err := r.FindReviewers(ctx, tx, authorID)
-	if err != nil {
-		return nil, err
+	status := model.PRStatusOpen
+	if input.Draft {
+		status = model.PRStatusDraft
 	}
 
-	_, err = tx.ExecContext(ctx, `
-		INSERT INTO pr (pr_id, pr_name, author_id)
-		VALUES ($1, $2, $3)
-		`, pullRequestID, pullRequestName, authorID)
+	var createdAt string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO pr (pr_id, pr_name, author_id, team_name, status)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		RETURNING created_at
+		`, pullRequestID, pullRequestName, authorID, input.TeamName, status).Scan(&createdAt)
 	if err != nil {
 		log.Printf("exec error: %v", err)
 		return nil, fmt.Errorf("exec error: %w", err)
 	}
 
-	for _, reviewer := range reviewers {
-		_, err = tx.ExecContext(ctx, `
-		INSERT INTO reviewer_x_pr (user_id, pr_id)
-		VALUES ($1, $2)
-		`, reviewer, pullRequestID)
-		if err != nil {
-			log.Printf("exec error: %v", err)
-			return nil, fmt.Errorf("exec error: %w", err)
-		}
+	if err = r.RecordStatusChange(ctx, tx, pullRequestID, "", status); err != nil {
+		return nil, err
 	}
 
-	if err := tx.Commit(); err != nil {
-		log.Printf("commit transaction error: %v", err)
-		return nil, fmt.Errorf("commit transaction error: %w", err)
+	if err = r.AddChangedFiles(ctx, tx, pullRequestID, input.ChangedFiles); err != nil {
+		return nil, err
+	}
+
+	assignedReviewers := []model.Reviewer{}
+	understaffed := false
+	if !input.Draft {
+		assignedReviewers, understaffed, err = r.AssignReviewers(ctx, tx, pullRequestID, authorID, input.TeamName, input.OwnerIDs, input.OwnerTeams, pick)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return &model.PullRequest{
+	pr := &model.PullRequest{
 		PullRequestShort: model.PullRequestShort{
 			PullRequestID:   pullRequestID,
 			PullRequestName: pullRequestName,
 			AuthorID:        authorID,
-			Status:          "OPEN",
+			Status:          status,
 		},
-		AssignedReviewers: reviewers,
-	}, nil
-}
-
-func (r *PRPostgresRepository) MergePR(ctx context.Context, pullRequestID string) (*model.PullRequest, error) {
-	ok, err := r.PRExists(ctx, pullRequestID)
+		TeamName:          input.TeamName,
+		AssignedReviewers: assignedReviewers,
+		CreatedAt:         createdAt,
+		Understaffed:      understaffed,
+	}
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditPRCreate,
+		entityType: model.AuditEntityPullRequest,
+		entityID:   pullRequestID,
+		after:      pr,
+	})
 	if err != nil {
 		return nil, err
 	}
-	if !ok {
-		log.Printf("pr doesn't exist: %s", pullRequestID)
-		return nil, model.NewNotFoundError()
+
+	if len(pr.AssignedReviewers) > 0 {
+		err = writeOutboxEvents(ctx, tx, outboxEntry{eventType: model.WebhookEventReviewersAssigned, data: pr})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
 	}
+	return pr, nil
+}
 
+// MarkReady moves a draft PR to OPEN and assigns reviewers the same way CreatePR does.
+func (r *PRPostgresRepository) MarkReady(ctx context.Context, pullRequestID string, ownerIDs, ownerTeams []string, pick model.ReviewerPicker) (*model.PullRequest, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		log.Printf("begin transaction error: %v", err)
@@ -107,228 +132,389 @@ func (r *PRPostgresRepository) MergePR(ctx context.Context, pullRequestID string
 	}
 	defer func() {
 		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			log.Printf("rollback transaction error in MergePR: %v", err)
+			log.Printf("rollback transaction error in MarkReady: %v", err)
 		}
 	}()
 
-	status, err := r.GetStatus(ctx, tx, pullRequestID)
+	before, err := r.GetPR(ctx, tx, pullRequestID)
 	if err != nil {
 		return nil, err
 	}
-	if status == "MERGED" {
-		var pr *model.PullRequest
-		pr, err = r.GetPR(ctx, tx, pullRequestID)
+
+	if err = r.SetStatus(ctx, tx, pullRequestID, model.PRStatusDraft, model.PRStatusOpen); err != nil {
+		return nil, err
+	}
+
+	pr, err := r.GetPR(ctx, tx, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	pr.AssignedReviewers, pr.Understaffed, err = r.AssignReviewers(ctx, tx, pullRequestID, pr.AuthorID, pr.TeamName, ownerIDs, ownerTeams, pick)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditPRMarkReady,
+		entityType: model.AuditEntityPullRequest,
+		entityID:   pullRequestID,
+		before:     before,
+		after:      pr,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pr.AssignedReviewers) > 0 {
+		err = writeOutboxEvents(ctx, tx, outboxEntry{eventType: model.WebhookEventReviewersAssigned, data: pr})
 		if err != nil {
 			return nil, err
 		}
-		if err = tx.Commit(); err != nil {
-			log.Printf("commit transaction error: %v", err)
-			return nil, fmt.Errorf("commit transaction error: %w", err)
-		}
-		return pr, nil
 	}
 
-	row := tx.QueryRowContext(ctx, `
-	UPDATE pr
-	SET status = 'MERGED', merged_at = CURRENT_TIMESTAMP
-	WHERE pr_id = $1
-	RETURNING pr_id, pr_name, author_id, status, merged_at
-	`, pullRequestID)
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return pr, nil
+}
 
-	var pr model.PullRequest
-	if err = row.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.MergedAt); err != nil {
-		log.Printf("pr doesn't exist: %s", pullRequestID)
-		return nil, model.NewNotFoundError()
+// UpdateStatus moves the PR from one status to another, failing if the PR is no longer in the expected status.
+func (r *PRPostgresRepository) UpdateStatus(ctx context.Context, pullRequestID, from, to string) (*model.PullRequest, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
 	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error in UpdateStatus: %v", err)
+		}
+	}()
 
-	reviewers, err := r.GetReviewers(ctx, tx, pullRequestID)
+	before, err := r.GetPR(ctx, tx, pullRequestID)
 	if err != nil {
 		return nil, err
 	}
 
-	pr.AssignedReviewers = reviewers
+	if err = r.SetStatus(ctx, tx, pullRequestID, from, to); err != nil {
+		return nil, err
+	}
 
-	if err := tx.Commit(); err != nil {
+	pr, err := r.GetPR(ctx, tx, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditPRUpdateStatus,
+		entityType: model.AuditEntityPullRequest,
+		entityID:   pullRequestID,
+		before:     before,
+		after:      pr,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
 		log.Printf("commit transaction error: %v", err)
 		return nil, fmt.Errorf("commit transaction error: %w", err)
 	}
-	return &pr, nil
+	return pr, nil
 }
 
-func (r *PRPostgresRepository) ReassignPR(ctx context.Context, pullRequestID, oldReviewerID string) (*model.PullRequest, string, error) {
+func (r *PRPostgresRepository) GetPullRequest(ctx context.Context, pullRequestID string) (*model.PullRequest, error) {
 	ok, err := r.PRExists(ctx, pullRequestID)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 	if !ok {
 		log.Printf("pr doesn't exist: %s", pullRequestID)
-		return nil, "", model.NewNotFoundError()
+		return nil, model.NewNotFoundError()
 	}
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
 	if err != nil {
 		log.Printf("begin transaction error: %v", err)
-		return nil, "", fmt.Errorf("begin transaction error: %w", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
 	}
 	defer func() {
 		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			log.Printf("rollback transaction error in ReassignPR: %v", err)
+			log.Printf("rollback transaction error in GetPullRequest: %v", err)
 		}
 	}()
 
-	status, err := r.GetStatus(ctx, tx, pullRequestID)
+	pr, err := r.GetPR(ctx, tx, pullRequestID)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
-	if status == "MERGED" {
-		log.Printf("pr merged: %s", pullRequestID)
-		return nil, "", model.NewPRMergedsError()
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
 	}
+	return pr, nil
+}
 
-	exists, err := r.IsReviewer(ctx, tx, pullRequestID, oldReviewerID)
+// GetPRHistory returns the reviewer assignment periods of the PR and a timeline of its status
+// and reviewer changes. Changes made at the same time are ordered as they happen in a transaction:
+// status changes first, then unassignments, then assignments.
+func (r *PRPostgresRepository) GetPRHistory(ctx context.Context, pullRequestID string) (*model.PRHistory, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
 	if err != nil {
-		return nil, "", err
-	}
-	if !exists {
-		log.Printf("user is not a reviewer: %s", oldReviewerID)
-		return nil, "", model.NewNotAssignedError()
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
 	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error in GetPRHistory: %v", err)
+		}
+	}()
 
-	newReviewerID, err := r.FindNewReviewer(ctx, tx, pullRequestID, oldReviewerID)
+	var exists bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM pr WHERE pr_id = $1)
+		`, pullRequestID).Scan(&exists)
 	if err != nil {
-		return nil, "", err
+		log.Printf("query row error: %v", err)
+		return nil, fmt.Errorf("query row error: %w", err)
 	}
-	if newReviewerID == "" {
-		log.Printf("no new reviewer")
-		return nil, "", model.NewNoCandidateError()
+	if !exists {
+		log.Printf("pr doesn't exist: %s", pullRequestID)
+		return nil, model.NewNotFoundError()
 	}
 
-	_, err = tx.ExecContext(ctx,
-		`DELETE FROM reviewer_x_pr
-		WHERE pr_id = $1 AND user_id = $2`,
-		pullRequestID, oldReviewerID,
-	)
-	if err != nil {
-		log.Printf("exec error: %v", err)
-		return nil, "", fmt.Errorf("exec error: %w", err)
+	history := &model.PRHistory{
+		PullRequestID: pullRequestID,
+		Assignments:   []model.ReviewerAssignment{},
+		Timeline:      []model.PRHistoryEvent{},
 	}
 
-	_, err = tx.ExecContext(ctx,
-		`INSERT INTO reviewer_x_pr (pr_id, user_id)
-		VALUES ($1, $2)`,
-		pullRequestID, newReviewerID,
-	)
+	rows, err := tx.QueryContext(ctx, `
+		SELECT from_status, to_status, changed_at
+		FROM pr_status_change
+		WHERE pr_id = $1
+		ORDER BY change_id
+		`, pullRequestID)
 	if err != nil {
-		log.Printf("exec error: %v", err)
-		return nil, "", fmt.Errorf("exec error: %w", err)
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
 	}
-
-	var pr model.PullRequest
-	err = tx.QueryRowContext(ctx,
-		`SELECT pr_id, pr_name, author_id, status
-		FROM pr
-		WHERE pr_id = $1`,
-		pullRequestID,
-	).Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status)
-	if err != nil {
-		log.Printf("scan error: %v", err)
-		return nil, "", fmt.Errorf("scan error: %w", err)
+	for rows.Next() {
+		event := model.PRHistoryEvent{Type: model.PRHistoryStatusChanged}
+		var fromStatus sql.NullString
+		if err = rows.Scan(&fromStatus, &event.ToStatus, &event.At); err != nil {
+			rows.Close()
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		event.FromStatus = fromStatus.String
+		history.Timeline = append(history.Timeline, event)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
 	}
 
-	log.Printf("pr: %v", pr)
-	reviewers, err := r.GetReviewers(ctx, tx, pullRequestID)
+	rows, err = tx.QueryContext(ctx, `
+		SELECT user_id, assigned_at, reason, unassigned_at, unassign_reason
+		FROM reviewer_assignment
+		WHERE pr_id = $1
+		ORDER BY assignment_id
+		`, pullRequestID)
 	if err != nil {
-		return nil, "", err
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
 	}
+	defer rows.Close()
 
-	pr.AssignedReviewers = reviewers
+	unassignments := []model.PRHistoryEvent{}
+	for rows.Next() {
+		var assignment model.ReviewerAssignment
+		var unassignedAt sql.NullTime
+		var unassignReason sql.NullString
+		if err = rows.Scan(&assignment.UserID, &assignment.AssignedAt, &assignment.Reason, &unassignedAt, &unassignReason); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		history.Timeline = append(history.Timeline, model.PRHistoryEvent{
+			Type:       model.PRHistoryReviewerAssigned,
+			At:         assignment.AssignedAt,
+			ReviewerID: assignment.UserID,
+			Reason:     assignment.Reason,
+		})
+		if unassignedAt.Valid {
+			assignment.UnassignedAt = &unassignedAt.Time
+			assignment.UnassignReason = unassignReason.String
+			unassignments = append(unassignments, model.PRHistoryEvent{
+				Type:       model.PRHistoryReviewerUnassigned,
+				At:         unassignedAt.Time,
+				ReviewerID: assignment.UserID,
+				Reason:     assignment.UnassignReason,
+			})
+		}
+		history.Assignments = append(history.Assignments, assignment)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
 
-	if err := tx.Commit(); err != nil {
+	if err = tx.Commit(); err != nil {
 		log.Printf("commit transaction error: %v", err)
-		return nil, "", fmt.Errorf("commit transaction error: %w", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
 	}
-	return &pr, newReviewerID, nil
-}
 
-func (r *PRPostgresRepository) AuthorExists(ctx context.Context, authorID string) (bool, error) {
-	result := r.db.QueryRowContext(ctx, `
-		SELECT user_id
-		FROM users
-		WHERE user_id = $1`,
-		authorID)
+	history.Timeline = append(history.Timeline, unassignments...)
+	eventOrder := map[string]int{
+		model.PRHistoryStatusChanged:      0,
+		model.PRHistoryReviewerUnassigned: 1,
+		model.PRHistoryReviewerAssigned:   2,
+	}
+	slices.SortStableFunc(history.Timeline, func(a, b model.PRHistoryEvent) int {
+		if c := a.At.Compare(b.At); c != 0 {
+			return c
+		}
+		return cmp.Compare(eventOrder[a.Type], eventOrder[b.Type])
+	})
 
-	var id string
-	if err := result.Scan(&id); err != nil {
-		if err == sql.ErrNoRows {
-			return false, nil
+	return history, nil
+}
+
+// ListPRs returns a page of PRs ordered from the newest to the oldest and the cursor of the next page, if any.
+func (r *PRPostgresRepository) ListPRs(ctx context.Context, filter model.PRListFilter) ([]model.PullRequestSummary, *model.PRCursor, error) {
+	conditions := []string{"TRUE"}
+	args := []any{}
+	addCondition := func(condition string, values ...any) {
+		placeholders := make([]any, 0, len(values))
+		for _, value := range values {
+			args = append(args, value)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
 		}
-		log.Printf("scan error: %v", err)
-		return false, fmt.Errorf("scan error: %w", err)
+		conditions = append(conditions, fmt.Sprintf(condition, placeholders...))
 	}
 
-	return true, nil
-}
+	if filter.Status != "" {
+		addCondition("pr.status = %s", filter.Status)
+	}
+	if filter.AuthorID != "" {
+		addCondition("pr.author_id = %s", filter.AuthorID)
+	}
+	if filter.ReviewerID != "" {
+		addCondition("EXISTS (SELECT 1 FROM reviewer_x_pr AS rpr WHERE rpr.pr_id = pr.pr_id AND rpr.user_id = %s)", filter.ReviewerID)
+	}
+	if filter.TeamName != "" {
+		addCondition("pr.team_name = %s", filter.TeamName)
+	}
+	if filter.CreatedFrom != nil {
+		addCondition("pr.created_at >= %s", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		addCondition("pr.created_at < %s", *filter.CreatedTo)
+	}
+	if filter.MergedFrom != nil {
+		addCondition("pr.merged_at >= %s", *filter.MergedFrom)
+	}
+	if filter.MergedTo != nil {
+		addCondition("pr.merged_at < %s", *filter.MergedTo)
+	}
+	if filter.After != nil {
+		addCondition("(pr.created_at, pr.pr_id) < (%s, %s)", filter.After.CreatedAt, filter.After.PullRequestID)
+	}
+	args = append(args, filter.Limit+1)
 
-func (r *PRPostgresRepository) PRExists(ctx context.Context, pullRequestID string) (bool, error) {
-	result := r.db.QueryRowContext(ctx, `
-		SELECT pr_id
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT pr.pr_id, pr.pr_name, pr.author_id, pr.status, pr.created_at, pr.merged_at
 		FROM pr
-		WHERE pr_id = $1`,
-		pullRequestID)
+		WHERE %s
+		ORDER BY pr.created_at DESC, pr.pr_id DESC
+		LIMIT $%d
+		`, strings.Join(conditions, " AND "), len(args)), args...)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
 
-	var id string
-	if err := result.Scan(&id); err != nil {
-		if err == sql.ErrNoRows {
-			return false, nil
+	prs := []model.PullRequestSummary{}
+	var next *model.PRCursor
+	var last model.PRCursor
+	for rows.Next() {
+		if len(prs) == filter.Limit {
+			next = &last
+			break
 		}
-		log.Printf("scan error: %v", err)
-		return false, fmt.Errorf("scan error: %w", err)
+
+		var pr model.PullRequestSummary
+		var createdAt time.Time
+		var mergedAt sql.NullTime
+		if err = rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &createdAt, &mergedAt); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, nil, fmt.Errorf("scan error: %w", err)
+		}
+		pr.CreatedAt = createdAt.Format(time.RFC3339Nano)
+		if mergedAt.Valid {
+			pr.MergedAt = mergedAt.Time.Format(time.RFC3339Nano)
+		}
+
+		prs = append(prs, pr)
+		last = model.PRCursor{CreatedAt: createdAt, PullRequestID: pr.PullRequestID}
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, nil, fmt.Errorf("rows error: %w", err)
 	}
 
-	return true, nil
+	return prs, next, nil
 }
 
-func (r *PRPostgresRepository) FindReviewers(ctx context.Context, tx *sql.Tx, authorID string) ([]string, error) {
-	rows, err := tx.QueryContext(ctx, `
-		SELECT user_id FROM users
-		WHERE team_name = (
-			SELECT team_name
-			FROM users
-			WHERE user_id = $1)
-		AND is_active = true
-		AND user_id != $1
-		ORDER BY RANDOM()
-		LIMIT 2
-		`, authorID)
+func (r *PRPostgresRepository) GetChangedFiles(ctx context.Context, pullRequestID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT file_path
+		FROM pr_file
+		WHERE pr_id = $1
+		`, pullRequestID)
 	if err != nil {
 		log.Printf("query error: %v", err)
 		return nil, fmt.Errorf("query error: %w", err)
 	}
 	defer rows.Close()
 
-	reviewers := []string{}
+	files := []string{}
 	for rows.Next() {
-		var s string
-		err := rows.Scan(&s)
-		if err != nil {
+		var file string
+		if err = rows.Scan(&file); err != nil {
 			log.Printf("scan error: %v", err)
 			return nil, fmt.Errorf("scan error: %w", err)
 		}
-
-		reviewers = append(reviewers, s)
+		files = append(files, file)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
 	}
 
-	return reviewers, nil
+	return files, nil
 }
 
-func (r *PRPostgresRepository) GetStatus(ctx context.Context, tx *sql.Tx, pullRequestID string) (string, error) {
+// GetStatusBeforeClose returns the status the closed PR had before it was closed, taken from
+// its status history. PRs without history are assumed to have been open.
+func (r *PRPostgresRepository) GetStatusBeforeClose(ctx context.Context, pullRequestID string) (string, error) {
 	var status string
-	err := tx.QueryRowContext(ctx, `
-	SELECT status
-	FROM pr
-	WHERE pr_id = $1
-	`, pullRequestID).Scan(&status)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT from_status
+		FROM pr_status_change
+		WHERE pr_id = $1 AND to_status = 'CLOSED' AND from_status IS NOT NULL
+		ORDER BY change_id DESC
+		LIMIT 1
+		`, pullRequestID).Scan(&status)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.PRStatusOpen, nil
+		}
 		log.Printf("query row error: %v", err)
 		return "", fmt.Errorf("query row error: %w", err)
 	}
@@ -336,50 +522,951 @@ func (r *PRPostgresRepository) GetStatus(ctx context.Context, tx *sql.Tx, pullRe
 	return status, nil
 }
 
-func (r *PRPostgresRepository) IsReviewer(ctx context.Context, tx *sql.Tx, pullRequestID, userID string) (bool, error) {
-	var exists bool
-	err := tx.QueryRowContext(ctx,
-		`SELECT EXISTS(
-			SELECT 1
-			FROM reviewer_x_pr
-			WHERE pr_id = $1 AND user_id = $2)`,
-		pullRequestID, userID,
-	).Scan(&exists)
+// MergePR merges the open PR once it's approved. With a non-empty forcedBy the PR is merged
+// without approvals, forcedBy is the admin doing it.
+func (r *PRPostgresRepository) MergePR(ctx context.Context, pullRequestID, forcedBy string) (*model.PullRequest, error) {
+	ok, err := r.PRExists(ctx, pullRequestID)
 	if err != nil {
-		log.Printf("scan error: %v", err)
-		return false, fmt.Errorf("scan error: %w", err)
+		return nil, err
+	}
+	if !ok {
+		log.Printf("pr doesn't exist: %s", pullRequestID)
+		return nil, model.NewNotFoundError()
 	}
 
-	return exists, nil
-}
-
-func (r *PRPostgresRepository) FindNewReviewer(ctx context.Context, tx *sql.Tx, pullRequestID, oldReviewerID string) (string, error) {
-	var newReviewerID string
-	findReviewerQuery := `
-        SELECT user_id
-        FROM users
-        WHERE team_name = (SELECT team_name FROM users WHERE user_id = $1)
-        AND user_id != $1
-        AND user_id != (SELECT author_id FROM pr WHERE pr_id = $2)
-        AND is_active = true
-        AND user_id NOT IN (
-            SELECT user_id FROM reviewer_x_pr WHERE pr_id = $2
-        )
-        ORDER BY RANDOM()
-        LIMIT 1
-    `
-
-	err := tx.QueryRowContext(ctx, findReviewerQuery, oldReviewerID, pullRequestID).Scan(&newReviewerID)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Println("no new reviewer")
-			return "", nil
-		}
-		log.Printf("query row error: %v", err)
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error in MergePR: %v", err)
+		}
+	}()
+
+	status, err := r.GetStatus(ctx, tx, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+	if status == model.PRStatusMerged {
+		var pr *model.PullRequest
+		pr, err = r.GetPR(ctx, tx, pullRequestID)
+		if err != nil {
+			return nil, err
+		}
+		if err = tx.Commit(); err != nil {
+			log.Printf("commit transaction error: %v", err)
+			return nil, fmt.Errorf("commit transaction error: %w", err)
+		}
+		return pr, nil
+	}
+	if status != model.PRStatusOpen {
+		log.Printf("pr is not open: %s", pullRequestID)
+		return nil, model.NewInvalidTransitionError(status, model.PRStatusMerged)
+	}
+
+	approved, err := r.IsApproved(ctx, tx, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+	if !approved && forcedBy == "" {
+		log.Printf("pr is not approved: %s", pullRequestID)
+		return nil, model.NewNotApprovedError()
+	}
+
+	before, err := r.GetPR(ctx, tx, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The admin is stored only if the merge actually bypassed the check.
+	var mergedBy sql.NullString
+	if !approved {
+		mergedBy = sql.NullString{String: forcedBy, Valid: true}
+	}
+	row := tx.QueryRowContext(ctx, `
+	UPDATE pr
+	SET status = 'MERGED', merged_at = CURRENT_TIMESTAMP, force_merged = $2, force_merged_by = $3
+	WHERE pr_id = $1
+	RETURNING pr_id, pr_name, author_id, status, created_at, merged_at, force_merged, COALESCE(force_merged_by, '')
+	`, pullRequestID, !approved, mergedBy)
+
+	var pr model.PullRequest
+	if err = row.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.ForceMerged, &pr.ForceMergedBy); err != nil {
+		log.Printf("pr doesn't exist: %s", pullRequestID)
+		return nil, model.NewNotFoundError()
+	}
+
+	if err = r.RecordStatusChange(ctx, tx, pullRequestID, status, model.PRStatusMerged); err != nil {
+		return nil, err
+	}
+
+	if err = r.FillReviewers(ctx, tx, &pr); err != nil {
+		return nil, err
+	}
+
+	after, err := r.GetPR(ctx, tx, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditPRMerge,
+		entityType: model.AuditEntityPullRequest,
+		entityID:   pullRequestID,
+		before:     before,
+		after:      after,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeOutboxEvents(ctx, tx, outboxEntry{eventType: model.WebhookEventPRMerged, data: after})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return &pr, nil
+}
+
+func (r *PRPostgresRepository) ReassignPR(ctx context.Context, pullRequestID, oldReviewerID string, pick model.ReviewerPicker) (*model.PullRequest, string, error) {
+	ok, err := r.PRExists(ctx, pullRequestID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !ok {
+		log.Printf("pr doesn't exist: %s", pullRequestID)
+		return nil, "", model.NewNotFoundError()
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, "", fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error in ReassignPR: %v", err)
+		}
+	}()
+
+	status, err := r.GetStatus(ctx, tx, pullRequestID)
+	if err != nil {
+		return nil, "", err
+	}
+	if status == model.PRStatusMerged {
+		log.Printf("pr merged: %s", pullRequestID)
+		return nil, "", model.NewPRMergedsError()
+	}
+	if status != model.PRStatusOpen {
+		log.Printf("pr is not open: %s", pullRequestID)
+		return nil, "", model.NewPRNotOpenError()
+	}
+
+	exists, err := r.IsReviewer(ctx, tx, pullRequestID, oldReviewerID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !exists {
+		log.Printf("user is not a reviewer: %s", oldReviewerID)
+		return nil, "", model.NewNotAssignedError()
+	}
+
+	overstaffed, err := r.IsOverstaffed(ctx, tx, pullRequestID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var newReviewerID string
+	var isFallback bool
+	if !overstaffed {
+		newReviewerID, isFallback, err = r.FindNewReviewer(ctx, tx, pullRequestID, []string{oldReviewerID}, pick)
+		if err != nil {
+			return nil, "", err
+		}
+		if newReviewerID == "" {
+			log.Printf("no new reviewer")
+			return nil, "", model.NewNoCandidateError()
+		}
+	}
+
+	reviewers, err := r.GetReviewers(ctx, tx, pullRequestID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err = r.RemoveReviewer(ctx, tx, pullRequestID, oldReviewerID, model.AssignmentReasonReassign); err != nil {
+		return nil, "", err
+	}
+
+	if newReviewerID != "" {
+		if err = r.AddReviewer(ctx, tx, pullRequestID, newReviewerID, isFallback, model.AssignmentReasonReassign); err != nil {
+			return nil, "", err
+		}
+	}
+
+	pr, err := r.GetPR(ctx, tx, pullRequestID)
+	if err != nil {
+		return nil, "", err
+	}
+	log.Printf("pr: %v", pr)
+
+	newReviewers := make([]string, 0, len(pr.AssignedReviewers))
+	for _, reviewer := range pr.AssignedReviewers {
+		newReviewers = append(newReviewers, reviewer.UserID)
+	}
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditPRReassign,
+		entityType: model.AuditEntityPullRequest,
+		entityID:   pullRequestID,
+		before:     reviewersSnapshot{Reviewers: reviewers},
+		after:      reviewersSnapshot{Reviewers: newReviewers},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var added []string
+	if newReviewerID != "" {
+		added = []string{newReviewerID}
+	}
+	err = writeOutboxEvents(ctx, tx, reassignmentEntry(pullRequestID, []string{oldReviewerID}, added, model.AssignmentReasonReassign))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, "", fmt.Errorf("commit transaction error: %w", err)
+	}
+	return pr, newReviewerID, nil
+}
+
+func (r *PRPostgresRepository) SubmitReview(ctx context.Context, pullRequestID, reviewerID, state string) (*model.PullRequest, error) {
+	ok, err := r.PRExists(ctx, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Printf("pr doesn't exist: %s", pullRequestID)
+		return nil, model.NewNotFoundError()
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error in SubmitReview: %v", err)
+		}
+	}()
+
+	status, err := r.GetStatus(ctx, tx, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+	if status == model.PRStatusMerged {
+		log.Printf("pr merged: %s", pullRequestID)
+		return nil, model.NewPRMergedReviewError()
+	}
+	if status != model.PRStatusOpen {
+		log.Printf("pr is not open: %s", pullRequestID)
+		return nil, model.NewPRNotOpenError()
+	}
+
+	before, err := r.GetPR(ctx, tx, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE reviewer_x_pr
+		SET state = $3, reviewed_at = CURRENT_TIMESTAMP
+		WHERE pr_id = $1 AND user_id = $2
+		`, pullRequestID, reviewerID, state)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return nil, fmt.Errorf("exec error: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("RowsAffected error: %v", err)
+		return nil, fmt.Errorf("RowsAffected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		log.Printf("user is not a reviewer: %s", reviewerID)
+		return nil, model.NewNotAssignedError()
+	}
+
+	pr, err := r.GetPR(ctx, tx, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditPRReview,
+		entityType: model.AuditEntityPullRequest,
+		entityID:   pullRequestID,
+		before:     before,
+		after:      pr,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return pr, nil
+}
+
+func (r *PRPostgresRepository) AuthorExists(ctx context.Context, authorID string) (bool, error) {
+	result := r.db.QueryRowContext(ctx, `
+		SELECT user_id
+		FROM users
+		WHERE user_id = $1`,
+		authorID)
+
+	var id string
+	if err := result.Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		log.Printf("scan error: %v", err)
+		return false, fmt.Errorf("scan error: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *PRPostgresRepository) PRExists(ctx context.Context, pullRequestID string) (bool, error) {
+	result := r.db.QueryRowContext(ctx, `
+		SELECT pr_id
+		FROM pr
+		WHERE pr_id = $1`,
+		pullRequestID)
+
+	var id string
+	if err := result.Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		log.Printf("scan error: %v", err)
+		return false, fmt.Errorf("scan error: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetUserTeams returns the names of the teams the user belongs to.
+func (r *PRPostgresRepository) GetUserTeams(ctx context.Context, tx *sql.Tx, userID string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT team_name
+		FROM team_member
+		WHERE user_id = $1
+		ORDER BY team_name
+		`, userID)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	teams := []string{}
+	for rows.Next() {
+		var teamName string
+		if err := rows.Scan(&teamName); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		teams = append(teams, teamName)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return teams, nil
+}
+
+// ResolvePRTeam returns the team a new PR of the author belongs to. The requested team must be one
+// of the author's teams; without it the author's only team is used, or none if the author has no team.
+func (r *PRPostgresRepository) ResolvePRTeam(ctx context.Context, authorID, teamName string) (string, error) {
+	ok, err := r.AuthorExists(ctx, authorID)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		log.Printf("author doesn't exist: %s", authorID)
+		return "", model.NewNotFoundError()
+	}
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return "", fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	teams, err := r.GetUserTeams(ctx, tx, authorID)
+	if err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return "", fmt.Errorf("commit transaction error: %w", err)
+	}
+
+	switch {
+	case teamName != "":
+		if !slices.Contains(teams, teamName) {
+			log.Printf("author %s is not a member of team %s", authorID, teamName)
+			return "", model.NewInvalidFieldError("team_name")
+		}
+		return teamName, nil
+	case len(teams) > 1:
+		log.Printf("author %s belongs to several teams", authorID)
+		return "", model.NewEmptyFieldError("team_name")
+	case len(teams) == 1:
+		return teams[0], nil
+	}
+	return "", nil
+}
+
+func (r *PRPostgresRepository) GetReviewerPool(ctx context.Context, tx *sql.Tx, teamName string, excludedIDs []string) (model.ReviewerPool, error) {
+	pool := model.ReviewerPool{FallbackCandidates: [][]model.ReviewerCandidate{}}
+	if teamName == "" {
+		pool.Strategy = model.StrategyLeastLoaded
+		pool.ReviewersRequired = model.DefaultReviewersRequired
+		return pool, nil
+	}
+
+	err := tx.QueryRowContext(ctx, `
+		SELECT assignment_strategy, reviewers_required
+		FROM team
+		WHERE team_name = $1
+		`, teamName).Scan(&pool.Strategy, &pool.ReviewersRequired)
+	if err != nil {
+		log.Printf("query row error: %v", err)
+		return pool, fmt.Errorf("query row error: %w", err)
+	}
+
+	pool.Candidates, err = r.GetCandidates(ctx, tx, []string{}, []string{teamName}, excludedIDs)
+	if err != nil {
+		return pool, err
+	}
+
+	fallbackTeams, err := r.GetFallbackTeams(ctx, tx, teamName)
+	if err != nil {
+		return pool, err
+	}
+	ancestors, err := r.GetAncestorTeams(ctx, tx, []string{teamName})
+	if err != nil {
+		return pool, err
+	}
+	for _, ancestor := range ancestors[teamName] {
+		if !slices.Contains(fallbackTeams, ancestor) {
+			fallbackTeams = append(fallbackTeams, ancestor)
+		}
+	}
+
+	for _, fallbackTeam := range fallbackTeams {
+		candidates, err := r.GetCandidates(ctx, tx, []string{}, []string{fallbackTeam}, excludedIDs)
+		if err != nil {
+			return pool, err
+		}
+		pool.FallbackCandidates = append(pool.FallbackCandidates, candidates)
+	}
+
+	return pool, nil
+}
+
+// GetAncestorTeams returns the parent chain of every given team, nearest parent first.
+func (r *PRPostgresRepository) GetAncestorTeams(ctx context.Context, tx *sql.Tx, teamNames []string) (map[string][]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		WITH RECURSIVE ancestor AS (
+			SELECT team_name, parent_team_name, 1 AS depth
+			FROM team
+			WHERE team_name = ANY($1) AND parent_team_name IS NOT NULL
+			UNION ALL
+			SELECT a.team_name, t.parent_team_name, a.depth + 1
+			FROM ancestor AS a
+			JOIN team AS t ON t.team_name = a.parent_team_name
+			WHERE t.parent_team_name IS NOT NULL
+		)
+		SELECT team_name, parent_team_name
+		FROM ancestor
+		ORDER BY team_name, depth
+		`, teamNames)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	ancestors := map[string][]string{}
+	for rows.Next() {
+		var teamName, ancestor string
+		if err := rows.Scan(&teamName, &ancestor); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		ancestors[teamName] = append(ancestors[teamName], ancestor)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return ancestors, nil
+}
+
+func (r *PRPostgresRepository) GetCodeOwnerRules(ctx context.Context, teamName string) ([]model.CodeOwnerRule, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT position, pattern, owner_user_id, owner_team_name
+		FROM code_owner_rule
+		WHERE team_name = $1
+		ORDER BY position
+		`, teamName)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCodeOwnerRules(rows)
+}
+
+func (r *PRPostgresRepository) GetFallbackTeams(ctx context.Context, tx *sql.Tx, teamName string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT fallback_team_name
+		FROM team_fallback
+		WHERE team_name = $1
+		ORDER BY priority
+		`, teamName)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	fallbackTeams := []string{}
+	for rows.Next() {
+		var fallbackTeam string
+		if err := rows.Scan(&fallbackTeam); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		fallbackTeams = append(fallbackTeams, fallbackTeam)
+	}
+
+	return fallbackTeams, nil
+}
+
+// GetLeadCandidates returns available leads of the team when it requires a lead review
+// and none of the reviewers is its lead. Otherwise no slot has to be reserved and nil is returned.
+func (r *PRPostgresRepository) GetLeadCandidates(ctx context.Context, tx *sql.Tx, teamName string, reviewers, excludedIDs []string) ([]model.ReviewerCandidate, error) {
+	if teamName == "" {
+		return nil, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT tm.user_id
+		FROM team AS t
+		JOIN team_member AS tm ON tm.team_name = t.team_name
+		WHERE t.team_name = $1 AND t.require_lead_review AND tm.role = 'lead'
+		`, teamName)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	leadIDs := []string{}
+	for rows.Next() {
+		var leadID string
+		if err := rows.Scan(&leadID); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		if slices.Contains(reviewers, leadID) {
+			return nil, nil
+		}
+		leadIDs = append(leadIDs, leadID)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	if len(leadIDs) == 0 {
+		return nil, nil
+	}
+
+	return r.GetCandidates(ctx, tx, leadIDs, []string{}, excludedIDs)
+}
+
+// GetCandidates returns active team members listed in userIDs or belonging to any of teamNames,
+// together with the load information used by assignment strategies.
+func (r *PRPostgresRepository) GetCandidates(ctx context.Context, tx *sql.Tx, userIDs, teamNames, excludedIDs []string) ([]model.ReviewerCandidate, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT u.user_id, u.review_weight, u.max_open_reviews, COUNT(pr.pr_id), MAX(rpr.assigned_at)
+		FROM users AS u
+		LEFT JOIN reviewer_x_pr AS rpr ON rpr.user_id = u.user_id
+		LEFT JOIN pr ON pr.pr_id = rpr.pr_id AND pr.status = 'OPEN'
+		WHERE EXISTS (
+			SELECT 1 FROM team_member AS tm
+			WHERE tm.user_id = u.user_id
+			AND (u.user_id = ANY($1) OR tm.team_name = ANY($2))
+		)
+		AND u.is_active = true
+		AND NOT EXISTS (
+			SELECT 1 FROM user_absence AS a
+			WHERE a.user_id = u.user_id
+			AND a.absent_from <= CURRENT_TIMESTAMP AND a.absent_to > CURRENT_TIMESTAMP
+		)
+		AND u.user_id != ALL($3)
+		GROUP BY u.user_id
+		HAVING u.max_open_reviews IS NULL OR COUNT(pr.pr_id) < u.max_open_reviews
+		`, userIDs, teamNames, excludedIDs)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := []model.ReviewerCandidate{}
+	for rows.Next() {
+		var candidate model.ReviewerCandidate
+		var maxOpenReviews sql.NullInt64
+		var lastAssignedAt sql.NullTime
+		err := rows.Scan(&candidate.UserID, &candidate.Weight, &maxOpenReviews, &candidate.OpenReviews, &lastAssignedAt)
+		if err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		if maxOpenReviews.Valid {
+			limit := int(maxOpenReviews.Int64)
+			candidate.MaxOpenReviews = &limit
+		}
+		candidate.LastAssignedAt = lastAssignedAt.Time
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, nil
+}
+
+func (r *PRPostgresRepository) GetStatus(ctx context.Context, tx *sql.Tx, pullRequestID string) (string, error) {
+	var status string
+	err := tx.QueryRowContext(ctx, `
+	SELECT status
+	FROM pr
+	WHERE pr_id = $1
+	`, pullRequestID).Scan(&status)
+	if err != nil {
+		log.Printf("query row error: %v", err)
 		return "", fmt.Errorf("query row error: %w", err)
 	}
 
-	return newReviewerID, nil
+	return status, nil
+}
+
+// SetStatus changes the PR status only if it is still in the expected one,
+// so concurrent transitions cannot both succeed.
+func (r *PRPostgresRepository) SetStatus(ctx context.Context, tx *sql.Tx, pullRequestID, from, to string) error {
+	result, err := tx.ExecContext(ctx, `
+		UPDATE pr
+		SET status = $3
+		WHERE pr_id = $1 AND status = $2
+		`, pullRequestID, from, to)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return fmt.Errorf("exec error: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("RowsAffected error: %v", err)
+		return fmt.Errorf("RowsAffected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		log.Printf("pr status changed concurrently: %s", pullRequestID)
+		return model.NewInvalidTransitionError(from, to)
+	}
+
+	return r.RecordStatusChange(ctx, tx, pullRequestID, from, to)
+}
+
+// RecordStatusChange appends a status change to the PR history, from is empty for a new PR.
+func (r *PRPostgresRepository) RecordStatusChange(ctx context.Context, tx *sql.Tx, pullRequestID, from, to string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO pr_status_change (pr_id, from_status, to_status)
+		VALUES ($1, NULLIF($2, ''), $3)
+		`, pullRequestID, from, to)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return fmt.Errorf("exec error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PRPostgresRepository) AddChangedFiles(ctx context.Context, tx *sql.Tx, pullRequestID string, files []string) error {
+	for _, file := range files {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO pr_file (pr_id, file_path)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+			`, pullRequestID, file)
+		if err != nil {
+			log.Printf("exec error: %v", err)
+			return fmt.Errorf("exec error: %w", err)
+		}
+	}
+	return nil
+}
+
+// AssignReviewers picks and stores reviewers of the PR. It reports the PR as understaffed
+// when fewer candidates than the team requires are available, e.g. because everyone is at capacity.
+func (r *PRPostgresRepository) AssignReviewers(ctx context.Context, tx *sql.Tx, pullRequestID, authorID, teamName string, ownerIDs, ownerTeams []string, pick model.ReviewerPicker) ([]model.Reviewer, bool, error) {
+	pool, err := r.GetReviewerPool(ctx, tx, teamName, []string{authorID})
+	if err != nil {
+		return nil, false, err
+	}
+	pool.LeadCandidates, err = r.GetLeadCandidates(ctx, tx, teamName, []string{}, []string{authorID})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(ownerIDs) > 0 || len(ownerTeams) > 0 {
+		pool.OwnerCandidates, err = r.GetCandidates(ctx, tx, ownerIDs, ownerTeams, []string{authorID})
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	assignedReviewers := []model.Reviewer{}
+	for _, reviewer := range pick(pool, pool.ReviewersRequired) {
+		isFallback := pool.IsFallback(reviewer)
+		if err = r.AddReviewer(ctx, tx, pullRequestID, reviewer, isFallback, model.AssignmentReasonInitial); err != nil {
+			return nil, false, err
+		}
+		assignedReviewers = append(assignedReviewers, model.Reviewer{
+			UserID:     reviewer,
+			State:      model.ReviewStatePending,
+			IsFallback: isFallback,
+		})
+	}
+	return assignedReviewers, len(assignedReviewers) < pool.ReviewersRequired, nil
+}
+
+func (r *PRPostgresRepository) IsReviewer(ctx context.Context, tx *sql.Tx, pullRequestID, userID string) (bool, error) {
+	var exists bool
+	err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS(
+			SELECT 1
+			FROM reviewer_x_pr
+			WHERE pr_id = $1 AND user_id = $2)`,
+		pullRequestID, userID,
+	).Scan(&exists)
+	if err != nil {
+		log.Printf("scan error: %v", err)
+		return false, fmt.Errorf("scan error: %w", err)
+	}
+
+	return exists, nil
+}
+
+// FindNewReviewer picks one more reviewer for the PR from the PR's team,
+// skipping the PR's current reviewers, the author and excludedIDs.
+func (r *PRPostgresRepository) FindNewReviewer(ctx context.Context, tx *sql.Tx, pullRequestID string, excludedIDs []string, pick model.ReviewerPicker) (string, bool, error) {
+	var authorID, teamName string
+	err := tx.QueryRowContext(ctx, `
+		SELECT author_id, COALESCE(team_name, '')
+		FROM pr
+		WHERE pr_id = $1
+		`, pullRequestID).Scan(&authorID, &teamName)
+	if err != nil {
+		log.Printf("query row error: %v", err)
+		return "", false, fmt.Errorf("query row error: %w", err)
+	}
+
+	reviewers, err := r.GetReviewers(ctx, tx, pullRequestID)
+	if err != nil {
+		return "", false, err
+	}
+
+	keptReviewers := slices.DeleteFunc(slices.Clone(reviewers), func(userID string) bool {
+		return slices.Contains(excludedIDs, userID)
+	})
+	unavailableIDs := append(append(reviewers, authorID), excludedIDs...)
+
+	pool, err := r.GetReviewerPool(ctx, tx, teamName, unavailableIDs)
+	if err != nil {
+		return "", false, err
+	}
+	pool.LeadCandidates, err = r.GetLeadCandidates(ctx, tx, teamName, keptReviewers, unavailableIDs)
+	if err != nil {
+		return "", false, err
+	}
+
+	picked := pick(pool, 1)
+	if len(picked) == 0 {
+		log.Println("no new reviewer")
+		return "", false, nil
+	}
+
+	return picked[0], pool.IsFallback(picked[0]), nil
+}
+
+// ReassignOpenReviews hands OPEN reviews of the given users over to another candidate of the PR's team.
+// With teamName set only reviews of that team's PRs are handed over. A review is dropped without
+// replacement if the PR is overstaffed or there is no candidate left. The reason is kept in the
+// assignment history of the PRs.
+func (r *PRPostgresRepository) ReassignOpenReviews(ctx context.Context, tx *sql.Tx, userIDs []string, teamName, reason string, pick model.ReviewerPicker) ([]model.ReviewHandover, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT rpr.pr_id, rpr.user_id
+		FROM reviewer_x_pr AS rpr
+		JOIN pr ON pr.pr_id = rpr.pr_id
+		WHERE rpr.user_id = ANY($1) AND pr.status = 'OPEN'
+		AND ($2 = '' OR pr.team_name = $2)
+		ORDER BY pr.created_at, rpr.pr_id, rpr.user_id
+		`, userIDs, teamName)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	handovers := []model.ReviewHandover{}
+	for rows.Next() {
+		var handover model.ReviewHandover
+		if err = rows.Scan(&handover.PullRequestID, &handover.OldReviewerID); err != nil {
+			rows.Close()
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		handovers = append(handovers, handover)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	for i, handover := range handovers {
+		reviewers, err := r.GetReviewers(ctx, tx, handover.PullRequestID)
+		if err != nil {
+			return nil, err
+		}
+
+		overstaffed, err := r.IsOverstaffed(ctx, tx, handover.PullRequestID)
+		if err != nil {
+			return nil, err
+		}
+
+		var isFallback bool
+		handovers[i].Outcome = model.HandoverRemoved
+		if !overstaffed {
+			handovers[i].NewReviewerID, isFallback, err = r.FindNewReviewer(ctx, tx, handover.PullRequestID, userIDs, pick)
+			if err != nil {
+				return nil, err
+			}
+			handovers[i].Outcome = model.HandoverNoCandidate
+			if handovers[i].NewReviewerID != "" {
+				handovers[i].Outcome = model.HandoverReassigned
+			}
+		}
+
+		if err = r.RemoveReviewer(ctx, tx, handover.PullRequestID, handover.OldReviewerID, reason); err != nil {
+			return nil, err
+		}
+
+		newReviewers := slices.DeleteFunc(slices.Clone(reviewers), func(userID string) bool {
+			return userID == handover.OldReviewerID
+		})
+		if handovers[i].NewReviewerID != "" {
+			if err = r.AddReviewer(ctx, tx, handover.PullRequestID, handovers[i].NewReviewerID, isFallback, reason); err != nil {
+				return nil, err
+			}
+			newReviewers = append(newReviewers, handovers[i].NewReviewerID)
+		}
+
+		err = writeAuditEvents(ctx, tx, auditEntry{
+			action:     model.AuditPRReassign,
+			entityType: model.AuditEntityPullRequest,
+			entityID:   handover.PullRequestID,
+			before:     reviewersSnapshot{Reviewers: reviewers},
+			after:      reviewersSnapshot{Reviewers: newReviewers},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var added []string
+		if handovers[i].NewReviewerID != "" {
+			added = []string{handovers[i].NewReviewerID}
+		}
+		err = writeOutboxEvents(ctx, tx, reassignmentEntry(handover.PullRequestID, []string{handover.OldReviewerID}, added, reason))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return handovers, nil
+}
+
+// IsApproved reports whether the PR has the number of approvals required by the PR's team
+// and no reviewer has outstanding requested changes.
+func (r *PRPostgresRepository) IsApproved(ctx context.Context, tx *sql.Tx, pullRequestID string) (bool, error) {
+	var approved bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(rpr.user_id) FILTER (WHERE rpr.state = 'APPROVED') >= COALESCE(MAX(t.approvals_required), 0)
+			AND COUNT(rpr.user_id) FILTER (WHERE rpr.state = 'CHANGES_REQUESTED') = 0
+		FROM pr
+		LEFT JOIN team AS t ON t.team_name = pr.team_name
+		LEFT JOIN reviewer_x_pr AS rpr ON rpr.pr_id = pr.pr_id
+		WHERE pr.pr_id = $1
+		`, pullRequestID).Scan(&approved)
+	if err != nil {
+		log.Printf("query row error: %v", err)
+		return false, fmt.Errorf("query row error: %w", err)
+	}
+
+	return approved, nil
+}
+
+// IsOverstaffed reports whether the PR has more reviewers than its team currently requires,
+// so a leaving reviewer does not need a replacement.
+func (r *PRPostgresRepository) IsOverstaffed(ctx context.Context, tx *sql.Tx, pullRequestID string) (bool, error) {
+	var overstaffed bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT (
+			SELECT COUNT(*)
+			FROM reviewer_x_pr
+			WHERE pr_id = $1
+		) > COALESCE(t.reviewers_required, $2)
+		FROM pr
+		LEFT JOIN team AS t ON t.team_name = pr.team_name
+		WHERE pr.pr_id = $1
+		`, pullRequestID, model.DefaultReviewersRequired).Scan(&overstaffed)
+	if err != nil {
+		log.Printf("query row error: %v", err)
+		return false, fmt.Errorf("query row error: %w", err)
+	}
+
+	return overstaffed, nil
 }
 
 func (r *PRPostgresRepository) GetReviewers(ctx context.Context, tx *sql.Tx, pullRequestID string) ([]string, error) {
@@ -407,25 +1494,108 @@ func (r *PRPostgresRepository) GetReviewers(ctx context.Context, tx *sql.Tx, pul
 	return reviewers, nil
 }
 
+// AddReviewer assigns the user to the PR and opens a period of the assignment history.
+func (r *PRPostgresRepository) AddReviewer(ctx context.Context, tx *sql.Tx, pullRequestID, userID string, isFallback bool, reason string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO reviewer_x_pr (user_id, pr_id, is_fallback)
+		VALUES ($1, $2, $3)
+		`, userID, pullRequestID, isFallback)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return fmt.Errorf("exec error: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO reviewer_assignment (pr_id, user_id, reason)
+		VALUES ($1, $2, $3)
+		`, pullRequestID, userID, reason)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return fmt.Errorf("exec error: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveReviewer unassigns the user from the PR and closes their open period of the assignment history.
+func (r *PRPostgresRepository) RemoveReviewer(ctx context.Context, tx *sql.Tx, pullRequestID, userID, reason string) error {
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM reviewer_x_pr
+		WHERE pr_id = $1 AND user_id = $2
+		`, pullRequestID, userID)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return fmt.Errorf("exec error: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE reviewer_assignment
+		SET unassigned_at = CURRENT_TIMESTAMP, unassign_reason = $3
+		WHERE pr_id = $1 AND user_id = $2 AND unassigned_at IS NULL
+		`, pullRequestID, userID, reason)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return fmt.Errorf("exec error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PRPostgresRepository) GetReviewerStates(ctx context.Context, tx *sql.Tx, pullRequestID string) ([]model.Reviewer, error) {
+	rows, err := tx.QueryContext(ctx, `
+	SELECT user_id, state, is_fallback
+	FROM reviewer_x_pr
+	WHERE pr_id = $1`,
+		pullRequestID)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	reviewers := []model.Reviewer{}
+	for rows.Next() {
+		var reviewer model.Reviewer
+		if err := rows.Scan(&reviewer.UserID, &reviewer.State, &reviewer.IsFallback); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		reviewers = append(reviewers, reviewer)
+	}
+
+	return reviewers, nil
+}
+
+func (r *PRPostgresRepository) FillReviewers(ctx context.Context, tx *sql.Tx, pr *model.PullRequest) error {
+	reviewers, err := r.GetReviewerStates(ctx, tx, pr.PullRequestID)
+	if err != nil {
+		return err
+	}
+
+	pr.AssignedReviewers = reviewers
+	return nil
+}
+
 func (r *PRPostgresRepository) GetPR(ctx context.Context, tx *sql.Tx, pullRequestID string) (*model.PullRequest, error) {
 	row := tx.QueryRowContext(ctx, `
-		SELECT pr_name, author_id, status, merged_at
+		SELECT pr_name, author_id, COALESCE(team_name, ''), status, created_at, merged_at, force_merged,
+			COALESCE(force_merged_by, '')
 		FROM pr
 		WHERE pr_id = $1
 		`, pullRequestID)
 
 	var pr model.PullRequest
-	if err := row.Scan(&pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.MergedAt); err != nil {
+	var mergedAt sql.NullString
+	if err := row.Scan(&pr.PullRequestName, &pr.AuthorID, &pr.TeamName, &pr.Status, &pr.CreatedAt, &mergedAt, &pr.ForceMerged, &pr.ForceMergedBy); err != nil {
 		log.Printf("scan error: %v", err)
 		return nil, fmt.Errorf("scan error: %w", err)
 	}
 
-	reviewers, err := r.GetReviewers(ctx, tx, pullRequestID)
-	if err != nil {
+	pr.PullRequestID = pullRequestID
+	pr.MergedAt = mergedAt.String
+	if err := r.FillReviewers(ctx, tx, &pr); err != nil {
 		return nil, err
 	}
-	pr.AssignedReviewers = reviewers
-	pr.PullRequestID = pullRequestID
 
 	return &pr, nil
 }
@@ -3,24 +3,46 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/karambo3a/avito_test_task/internal/model"
 )
 
 type TeamPostgres interface {
 	AddTeam(ctx context.Context, team model.Team) (*model.Team, error)
-	GetTeam(ctx context.Context, teamName string) (*model.Team, error)
+	GetTeam(ctx context.Context, teamName string, withSubTeams bool) (*model.Team, error)
+	UpdateTeamSettings(ctx context.Context, settings model.TeamSettings) (*model.TeamSettings, error)
+	SetCodeOwners(ctx context.Context, owners model.CodeOwners) (*model.CodeOwners, error)
+	GetCodeOwners(ctx context.Context, teamName string) (*model.CodeOwners, error)
+	AddMembers(ctx context.Context, teamName string, members []model.TeamMember) (*model.Team, error)
+	RemoveMember(ctx context.Context, teamName, userID string, pick model.ReviewerPicker) ([]model.ReviewHandover, error)
+	RenameTeam(ctx context.Context, teamName, newTeamName string) (*model.Team, error)
+	DeleteTeam(ctx context.Context, teamName string, force bool, pick model.ReviewerPicker) ([]model.ReviewHandover, error)
+	DeactivateUsers(ctx context.Context, teamName string, userIDs []string, pick model.ReviewerPicker) ([]model.PRReassignment, error)
 }
 
 type UsersPostgres interface {
-	SetUserIsActive(ctx context.Context, userID string, isActive bool) (*model.User, error)
+	SetUserIsActive(ctx context.Context, userID string, isActive, reassign bool, pick model.ReviewerPicker) (*model.User, []model.ReviewHandover, error)
 	GetUserReview(ctx context.Context, userID string) ([]model.PullRequestShort, error)
+	MoveUser(ctx context.Context, userID, fromTeamName, teamName string, reassign bool, pick model.ReviewerPicker) (*model.User, []model.ReviewHandover, error)
+	AddAbsence(ctx context.Context, absence model.Absence) (*model.Absence, error)
+	GetTeamRoles(ctx context.Context, userID string) (map[string]string, error)
 }
 
 type PullRequestPostgres interface {
-	CreatePR(ctx context.Context, pullRequestID, pullRequestName, authorID string) (*model.PullRequest, error)
-	MergePR(ctx context.Context, pullRequestID string) (*model.PullRequest, error)
-	ReassignPR(ctx context.Context, pullRequestID, oldReviewerID string) (*model.PullRequest, string, error)
+	CreatePR(ctx context.Context, input model.CreatePRInput, pick model.ReviewerPicker) (*model.PullRequest, error)
+	MergePR(ctx context.Context, pullRequestID, forcedBy string) (*model.PullRequest, error)
+	MarkReady(ctx context.Context, pullRequestID string, ownerIDs, ownerTeams []string, pick model.ReviewerPicker) (*model.PullRequest, error)
+	UpdateStatus(ctx context.Context, pullRequestID, from, to string) (*model.PullRequest, error)
+	GetPullRequest(ctx context.Context, pullRequestID string) (*model.PullRequest, error)
+	GetPRHistory(ctx context.Context, pullRequestID string) (*model.PRHistory, error)
+	GetChangedFiles(ctx context.Context, pullRequestID string) ([]string, error)
+	GetStatusBeforeClose(ctx context.Context, pullRequestID string) (string, error)
+	ListPRs(ctx context.Context, filter model.PRListFilter) ([]model.PullRequestSummary, *model.PRCursor, error)
+	ReassignPR(ctx context.Context, pullRequestID, oldReviewerID string, pick model.ReviewerPicker) (*model.PullRequest, string, error)
+	SubmitReview(ctx context.Context, pullRequestID, reviewerID, state string) (*model.PullRequest, error)
+	ResolvePRTeam(ctx context.Context, authorID, teamName string) (string, error)
+	GetCodeOwnerRules(ctx context.Context, teamName string) ([]model.CodeOwnerRule, error)
 }
 
 type StatisticsPostgres interface {
@@ -28,11 +50,39 @@ type StatisticsPostgres interface {
 	GetTeamStatistics(ctx context.Context, teamName string) (*model.TeamStatistics, error)
 }
 
+type AuthPostgres interface {
+	CreateToken(ctx context.Context, token model.APIToken, tokenHash string) (*model.APIToken, error)
+	RevokeToken(ctx context.Context, tokenID string) (*model.APIToken, error)
+	GetIdentity(ctx context.Context, tokenHash string) (*model.Identity, error)
+}
+
+type AuditPostgres interface {
+	ListAuditEvents(ctx context.Context, filter model.AuditListFilter) ([]model.AuditEvent, *model.AuditCursor, error)
+}
+
+type WebhookPostgres interface {
+	CreateSubscription(ctx context.Context, subscription model.WebhookSubscription) (*model.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, subscriptionID string) error
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDispatch, error)
+	RecordAttempt(ctx context.Context, deliveryID int64, attempt model.WebhookAttempt, status string, retryIn time.Duration) error
+	ListDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter) ([]model.WebhookDelivery, error)
+	ReplayDelivery(ctx context.Context, deliveryID int64) (*model.WebhookDelivery, error)
+}
+
+type OutboxPostgres interface {
+	DrainOutbox(ctx context.Context, limit int, encode model.OutboxEncoder) (int, int64, error)
+}
+
 type Repository struct {
 	TeamPostgres
 	UsersPostgres
 	PullRequestPostgres
 	StatisticsPostgres
+	AuthPostgres
+	AuditPostgres
+	WebhookPostgres
+	OutboxPostgres
 }
 
 func NewRepository(db *sql.DB) *Repository {
@@ -41,5 +91,9 @@ func NewRepository(db *sql.DB) *Repository {
 		UsersPostgres:       NewUsersPostgresRepository(db),
 		PullRequestPostgres: NewPRPostgresRepository(db),
 		StatisticsPostgres:  NewStatisticsPostgresRepository(db),
+		AuthPostgres:        NewAuthPostgresRepository(db),
+		AuditPostgres:       NewAuditPostgresRepository(db),
+		WebhookPostgres:     NewWebhookPostgresRepository(db),
+		OutboxPostgres:      NewOutboxPostgresRepository(db),
 	}
 }
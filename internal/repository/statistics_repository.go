@@ -10,11 +10,12 @@ import (
 )
 
 type StatisticsPostgresRepository struct {
-	db *sql.DB
+	db  *sql.DB
+	prs *PRPostgresRepository
 }
 
 func NewStatisticsPostgresRepository(db *sql.DB) *StatisticsPostgresRepository {
-	return &StatisticsPostgresRepository{db: db}
+	return &StatisticsPostgresRepository{db: db, prs: NewPRPostgresRepository(db)}
 }
 
 func (r *StatisticsPostgresRepository) GetUserStatistics(ctx context.Context, userID string) (*model.UserStatistics, error) {
@@ -39,14 +40,22 @@ func (r *StatisticsPostgresRepository) GetUserStatistics(ctx context.Context, us
 
 	var stats model.UserStatistics
 	err = tx.QueryRowContext(ctx, `
-		SELECT user_id, username, team_name
+		SELECT user_id, username
 		FROM users
 		WHERE user_id = $1
-	`, userID).Scan(&stats.UserID, &stats.Username, &stats.TeamName)
+	`, userID).Scan(&stats.UserID, &stats.Username)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
 
+	stats.Teams, err = r.prs.GetUserTeams(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(stats.Teams) == 1 {
+		stats.TeamName = stats.Teams[0]
+	}
+
 	err = tx.QueryRowContext(ctx, `
 		SELECT COUNT(*)
 		FROM pr
@@ -85,6 +94,10 @@ func (r *StatisticsPostgresRepository) GetTeamStatistics(ctx context.Context, te
 	}
 
 	stats := model.TeamStatistics{TeamName: teamName}
+	stats.SubTeams, err = r.GetDescendantTeams(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
 
 	err = r.db.QueryRowContext(ctx, `
 		SELECT
@@ -92,9 +105,8 @@ func (r *StatisticsPostgresRepository) GetTeamStatistics(ctx context.Context, te
 			SUM(CASE WHEN status = 'MERGED' THEN 1 ELSE 0 END) as merged_prs,
 			SUM(CASE WHEN status = 'OPEN' THEN 1 ELSE 0 END) as open_prs
 		FROM pr
-		JOIN users as u ON pr.author_id = u.user_id
-		WHERE u.team_name = $1
-	`, teamName).Scan(&stats.TotalPRs, &stats.MergedPRs, &stats.OpenPRs)
+		WHERE team_name = $1 OR team_name = ANY($2)
+	`, teamName, stats.SubTeams).Scan(&stats.TotalPRs, &stats.MergedPRs, &stats.OpenPRs)
 	if err != nil {
 		log.Printf("scan error: %v", err)
 		return nil, fmt.Errorf("scan error: %w", err)
@@ -103,6 +115,45 @@ func (r *StatisticsPostgresRepository) GetTeamStatistics(ctx context.Context, te
 	return &stats, nil
 }
 
+// GetDescendantTeams returns all teams below the given one in the hierarchy ordered by name.
+func (r *StatisticsPostgresRepository) GetDescendantTeams(ctx context.Context, teamName string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH RECURSIVE descendant AS (
+			SELECT team_name
+			FROM team
+			WHERE parent_team_name = $1
+			UNION
+			SELECT t.team_name
+			FROM team AS t
+			JOIN descendant AS d ON t.parent_team_name = d.team_name
+		)
+		SELECT team_name
+		FROM descendant
+		ORDER BY team_name
+	`, teamName)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	descendants := []string{}
+	for rows.Next() {
+		var descendant string
+		if err := rows.Scan(&descendant); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		descendants = append(descendants, descendant)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return descendants, nil
+}
+
 func (r *StatisticsPostgresRepository) UserExists(ctx context.Context, userID string) (bool, error) {
 	result := r.db.QueryRowContext(ctx, `
 		SELECT user_id
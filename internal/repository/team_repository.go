@@ -1,20 +1,24 @@
 package repository
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"fmt"
 	"log"
+	"slices"
+	"time"
 
 	"github.com/karambo3a/avito_test_task/internal/model"
 )
 
 type TeamPostgresRepository struct {
-	db *sql.DB
+	db  *sql.DB
+	prs *PRPostgresRepository
 }
 
 func NewTeamPostgresRepository(db *sql.DB) *TeamPostgresRepository {
-	return &TeamPostgresRepository{db: db}
+	return &TeamPostgresRepository{db: db, prs: NewPRPostgresRepository(db)}
 }
 
 func (r *TeamPostgresRepository) TeamExists(ctx context.Context, tx *sql.Tx, teamName string) (bool, error) {
@@ -59,26 +63,44 @@ func (r *TeamPostgresRepository) AddTeam(ctx context.Context, team model.Team) (
 
 	_, err = tx.Exec(`
 			INSERT INTO team
-			(team_name)
-			VALUES ($1)
-			`, team.TeamName)
+			(team_name, assignment_strategy, reviewers_required, approvals_required, require_lead_review)
+			VALUES ($1, $2, $3, $4, $5)
+			`, team.TeamName, team.AssignmentStrategy, team.ReviewersRequired, team.ApprovalsRequired, team.RequireLeadReview)
 	if err != nil {
 		log.Printf("exec error: %v", err)
 		return nil, fmt.Errorf("exec error: %w", err)
 	}
 
+	if team.ParentTeamName != "" {
+		if err = r.SetParentTeam(ctx, tx, team.TeamName, team.ParentTeamName); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = r.SetFallbackTeams(ctx, tx, team.TeamName, team.FallbackTeams); err != nil {
+		return nil, err
+	}
+
 	for _, teamMember := range team.Members {
-		_, err := tx.Exec(`
-			INSERT INTO users
-			(user_id, username, team_name, is_active)
-			VALUES ($1, $2, $3, $4)
-			`, teamMember.UserID, teamMember.Username, team.TeamName, teamMember.IsActive)
-		if err != nil {
-			log.Printf("exec error: %v", err)
-			return nil, fmt.Errorf("exec error: %w", err)
+		if err = r.UpsertMember(ctx, tx, team.TeamName, teamMember, true); err != nil {
+			return nil, err
 		}
 	}
 
+	created, err := r.ReadTeam(ctx, tx, team.TeamName, false)
+	if err != nil {
+		return nil, err
+	}
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditTeamCreate,
+		entityType: model.AuditEntityTeam,
+		entityID:   team.TeamName,
+		after:      created,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		log.Printf("commit transaction error: %v", err)
 		return nil, fmt.Errorf("commit transaction error: %w", err)
@@ -86,7 +108,224 @@ func (r *TeamPostgresRepository) AddTeam(ctx context.Context, team model.Team) (
 	return &team, nil
 }
 
-func (r *TeamPostgresRepository) GetTeam(ctx context.Context, teamName string) (*model.Team, error) {
+// AddMembers adds users to the team. Existing users keep their other teams and their fields.
+func (r *TeamPostgresRepository) AddMembers(ctx context.Context, teamName string, members []model.TeamMember) (*model.Team, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	ok, err := r.TeamExists(ctx, tx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Printf("team doesn't exist: %s", teamName)
+		return nil, model.NewNotFoundError()
+	}
+
+	before, err := r.ReadTeam(ctx, tx, teamName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Existing users may belong to teams not led by the caller, so only their role in this team changes.
+	for _, member := range members {
+		if err = r.UpsertMember(ctx, tx, teamName, member, false); err != nil {
+			return nil, err
+		}
+	}
+
+	team, err := r.ReadTeam(ctx, tx, teamName, false)
+	if err != nil {
+		return nil, err
+	}
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditTeamAddMembers,
+		entityType: model.AuditEntityTeam,
+		entityID:   teamName,
+		before:     before,
+		after:      team,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Members that were already in the team only get their role updated.
+	added := []string{}
+	for _, member := range members {
+		if !slices.ContainsFunc(before.Members, func(m model.TeamMember) bool { return m.UserID == member.UserID }) &&
+			!slices.Contains(added, member.UserID) {
+			added = append(added, member.UserID)
+		}
+	}
+	if len(added) > 0 {
+		if err = writeOutboxEvents(ctx, tx, membersEntry(teamName, added, nil)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return team, nil
+}
+
+// RemoveMember detaches the user from the team and hands their OPEN reviews of the team's PRs over to other candidates.
+func (r *TeamPostgresRepository) RemoveMember(ctx context.Context, teamName, userID string, pick model.ReviewerPicker) ([]model.ReviewHandover, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	ok, err := r.TeamExists(ctx, tx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Printf("team doesn't exist: %s", teamName)
+		return nil, model.NewNotFoundError()
+	}
+
+	before, err := r.ReadTeam(ctx, tx, teamName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM team_member
+		WHERE user_id = $1 AND team_name = $2
+		`, userID, teamName)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return nil, fmt.Errorf("exec error: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("RowsAffected error: %v", err)
+		return nil, fmt.Errorf("RowsAffected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		log.Printf("user %s is not a member of team %s", userID, teamName)
+		return nil, model.NewNotFoundError()
+	}
+
+	after, err := r.ReadTeam(ctx, tx, teamName, false)
+	if err != nil {
+		return nil, err
+	}
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditTeamRemoveMember,
+		entityType: model.AuditEntityTeam,
+		entityID:   teamName,
+		before:     before,
+		after:      after,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = writeOutboxEvents(ctx, tx, membersEntry(teamName, nil, []string{userID})); err != nil {
+		return nil, err
+	}
+
+	handovers, err := r.prs.ReassignOpenReviews(ctx, tx, []string{userID}, teamName, model.AssignmentReasonMembership, pick)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return handovers, nil
+}
+
+// RenameTeam changes the team name, references to the team are updated by the database.
+func (r *TeamPostgresRepository) RenameTeam(ctx context.Context, teamName, newTeamName string) (*model.Team, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	ok, err := r.TeamExists(ctx, tx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Printf("team doesn't exist: %s", teamName)
+		return nil, model.NewNotFoundError()
+	}
+
+	ok, err = r.TeamExists(ctx, tx, newTeamName)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		log.Printf("team exists: %s", newTeamName)
+		return nil, model.NewTeamExistsError()
+	}
+
+	before, err := r.ReadTeam(ctx, tx, teamName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE team
+		SET team_name = $2
+		WHERE team_name = $1
+		`, teamName, newTeamName)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return nil, fmt.Errorf("exec error: %w", err)
+	}
+
+	team, err := r.ReadTeam(ctx, tx, newTeamName, false)
+	if err != nil {
+		return nil, err
+	}
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditTeamRename,
+		entityType: model.AuditEntityTeam,
+		entityID:   teamName,
+		before:     before,
+		after:      team,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return team, nil
+}
+
+// DeleteTeam removes the team, its members lose the membership and their OPEN reviews of the team's PRs
+// are handed over. A team that still has OPEN PRs is deleted only when forced.
+func (r *TeamPostgresRepository) DeleteTeam(ctx context.Context, teamName string, force bool, pick model.ReviewerPicker) ([]model.ReviewHandover, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		log.Printf("begin transaction error: %v", err)
@@ -107,10 +346,186 @@ func (r *TeamPostgresRepository) GetTeam(ctx context.Context, teamName string) (
 		return nil, model.NewNotFoundError()
 	}
 
+	var hasOpenPRs bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS(
+			SELECT 1
+			FROM pr
+			WHERE team_name = $1 AND status = 'OPEN'
+		)
+		`, teamName).Scan(&hasOpenPRs)
+	if err != nil {
+		log.Printf("query row error: %v", err)
+		return nil, fmt.Errorf("query row error: %w", err)
+	}
+	if hasOpenPRs && !force {
+		log.Printf("team has open prs: %s", teamName)
+		return nil, model.NewTeamHasOpenPRsError()
+	}
+
+	before, err := r.ReadTeam(ctx, tx, teamName, false)
+	if err != nil {
+		return nil, err
+	}
+
 	rows, err := tx.QueryContext(ctx, `
-		SELECT user_id, username, is_active
-		FROM users
+		DELETE FROM team_member
+		WHERE team_name = $1
+		RETURNING user_id
+		`, teamName)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	memberIDs := []string{}
+	for rows.Next() {
+		var memberID string
+		if err = rows.Scan(&memberID); err != nil {
+			rows.Close()
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		memberIDs = append(memberIDs, memberID)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	handovers, err := r.prs.ReassignOpenReviews(ctx, tx, memberIDs, teamName, model.AssignmentReasonMembership, pick)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM team
+		WHERE team_name = $1
+		`, teamName)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return nil, fmt.Errorf("exec error: %w", err)
+	}
+
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditTeamDelete,
+		entityType: model.AuditEntityTeam,
+		entityID:   teamName,
+		before:     before,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(memberIDs) > 0 {
+		if err = writeOutboxEvents(ctx, tx, membersEntry(teamName, nil, memberIDs)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return handovers, nil
+}
+
+// UpsertMember creates the user and adds them to the team. An existing user is updated
+// only with updateUser, otherwise only their role in the team is set.
+func (r *TeamPostgresRepository) UpsertMember(ctx context.Context, tx *sql.Tx, teamName string, member model.TeamMember, updateUser bool) error {
+	query := `
+		INSERT INTO users
+		(user_id, username, is_active, review_weight, max_open_reviews)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO NOTHING
+		`
+	if updateUser {
+		query = `
+		INSERT INTO users
+		(user_id, username, is_active, review_weight, max_open_reviews)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE
+		SET username = EXCLUDED.username,
+			is_active = EXCLUDED.is_active,
+			review_weight = EXCLUDED.review_weight,
+			max_open_reviews = EXCLUDED.max_open_reviews
+		`
+	}
+	_, err := tx.ExecContext(ctx, query, member.UserID, member.Username, member.IsActive, member.ReviewWeight, member.MaxOpenReviews)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return fmt.Errorf("exec error: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO team_member (team_name, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (team_name, user_id) DO UPDATE
+		SET role = EXCLUDED.role
+		`, teamName, member.UserID, member.Role)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return fmt.Errorf("exec error: %w", err)
+	}
+
+	return nil
+}
+
+// GetTeam returns the team with its members. With withSubTeams the whole subtree
+// of descendant teams is returned as well.
+func (r *TeamPostgresRepository) GetTeam(ctx context.Context, teamName string, withSubTeams bool) (*model.Team, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	ok, err := r.TeamExists(ctx, tx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Printf("team doesn't exist: %s", teamName)
+		return nil, model.NewNotFoundError()
+	}
+
+	team, err := r.ReadTeam(ctx, tx, teamName, withSubTeams)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+
+	return team, nil
+}
+
+// ReadTeam reads the team inside the transaction, recursing into sub-teams when withSubTeams is set.
+func (r *TeamPostgresRepository) ReadTeam(ctx context.Context, tx *sql.Tx, teamName string, withSubTeams bool) (*model.Team, error) {
+	var parentTeamName sql.NullString
+	team := model.Team{TeamName: teamName}
+	err := tx.QueryRowContext(ctx, `
+		SELECT assignment_strategy, reviewers_required, approvals_required, require_lead_review, parent_team_name
+		FROM team
 		WHERE team_name = $1
+		`, teamName).Scan(&team.AssignmentStrategy, &team.ReviewersRequired, &team.ApprovalsRequired, &team.RequireLeadReview, &parentTeamName)
+	if err != nil {
+		log.Printf("query row error: %v", err)
+		return nil, fmt.Errorf("query row error: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT u.user_id, u.username, u.is_active, tm.role, u.review_weight, u.max_open_reviews
+		FROM team_member AS tm
+		JOIN users AS u ON u.user_id = tm.user_id
+		WHERE tm.team_name = $1
 		`, teamName)
 	if err != nil {
 		log.Printf("query error: %v", err)
@@ -121,21 +536,1003 @@ func (r *TeamPostgresRepository) GetTeam(ctx context.Context, teamName string) (
 	members := []model.TeamMember{}
 	for rows.Next() {
 		var member model.TeamMember
-		err := rows.Scan(&member.UserID, &member.Username, &member.IsActive)
+		var maxOpenReviews sql.NullInt64
+		err := rows.Scan(&member.UserID, &member.Username, &member.IsActive, &member.Role, &member.ReviewWeight, &maxOpenReviews)
 		if err != nil {
 			log.Printf("scan error: %v", err)
 			return nil, fmt.Errorf("scan error: %w", err)
 		}
+		if maxOpenReviews.Valid {
+			limit := int(maxOpenReviews.Int64)
+			member.MaxOpenReviews = &limit
+		}
 		members = append(members, member)
 	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
 
-	if err := tx.Commit(); err != nil {
+	absences, err := r.GetUpcomingAbsences(ctx, tx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	for i := range members {
+		members[i].Absences = absences[members[i].UserID]
+	}
+
+	team.FallbackTeams, err = r.prs.GetFallbackTeams(ctx, tx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	team.Members = members
+	team.ParentTeamName = parentTeamName.String
+
+	if withSubTeams {
+		subTeamNames, err := r.GetSubTeams(ctx, tx, teamName)
+		if err != nil {
+			return nil, err
+		}
+		for _, subTeamName := range subTeamNames {
+			subTeam, err := r.ReadTeam(ctx, tx, subTeamName, true)
+			if err != nil {
+				return nil, err
+			}
+			team.SubTeams = append(team.SubTeams, *subTeam)
+		}
+	}
+
+	return &team, nil
+}
+
+// GetSubTeams returns the names of the direct sub-teams ordered by name.
+func (r *TeamPostgresRepository) GetSubTeams(ctx context.Context, tx *sql.Tx, teamName string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT team_name
+		FROM team
+		WHERE parent_team_name = $1
+		ORDER BY team_name
+		`, teamName)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	subTeams := []string{}
+	for rows.Next() {
+		var subTeam string
+		if err := rows.Scan(&subTeam); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		subTeams = append(subTeams, subTeam)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return subTeams, nil
+}
+
+// GetUpcomingAbsences returns current and future absences of the team members grouped by user.
+func (r *TeamPostgresRepository) GetUpcomingAbsences(ctx context.Context, tx *sql.Tx, teamName string) (map[string][]model.Absence, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT a.user_id, a.absent_from, a.absent_to
+		FROM user_absence AS a
+		JOIN team_member AS tm ON tm.user_id = a.user_id
+		WHERE tm.team_name = $1
+		AND a.absent_to > CURRENT_TIMESTAMP
+		ORDER BY a.absent_from
+		`, teamName)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	absences := map[string][]model.Absence{}
+	for rows.Next() {
+		var absence model.Absence
+		if err = rows.Scan(&absence.UserID, &absence.From, &absence.To); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		absences[absence.UserID] = append(absences[absence.UserID], absence)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return absences, nil
+}
+
+func (r *TeamPostgresRepository) UpdateTeamSettings(ctx context.Context, settings model.TeamSettings) (*model.TeamSettings, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	ok, err := r.TeamExists(ctx, tx, settings.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Printf("team doesn't exist: %s", settings.TeamName)
+		return nil, model.NewNotFoundError()
+	}
+
+	before, err := r.ReadTeam(ctx, tx, settings.TeamName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		UPDATE team
+		SET assignment_strategy = COALESCE(NULLIF($2, ''), assignment_strategy),
+			reviewers_required = COALESCE(NULLIF($3, 0), reviewers_required),
+			approvals_required = COALESCE($4, approvals_required),
+			require_lead_review = COALESCE($5, require_lead_review)
+		WHERE team_name = $1
+		RETURNING team_name, assignment_strategy, reviewers_required, approvals_required, require_lead_review
+		`, settings.TeamName, settings.AssignmentStrategy, settings.ReviewersRequired, settings.ApprovalsRequired, settings.RequireLeadReview)
+
+	var updated model.TeamSettings
+	updated.ApprovalsRequired = new(int)
+	updated.RequireLeadReview = new(bool)
+	if err = row.Scan(&updated.TeamName, &updated.AssignmentStrategy, &updated.ReviewersRequired, updated.ApprovalsRequired, updated.RequireLeadReview); err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("team doesn't exist: %s", settings.TeamName)
+			return nil, model.NewNotFoundError()
+		}
+		log.Printf("scan error: %v", err)
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	if settings.FallbackTeams != nil {
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM team_fallback
+			WHERE team_name = $1
+			`, settings.TeamName)
+		if err != nil {
+			log.Printf("exec error: %v", err)
+			return nil, fmt.Errorf("exec error: %w", err)
+		}
+
+		if err = r.SetFallbackTeams(ctx, tx, settings.TeamName, settings.FallbackTeams); err != nil {
+			return nil, err
+		}
+	}
+
+	updated.FallbackTeams, err = r.prs.GetFallbackTeams(ctx, tx, settings.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.ParentTeamName != nil {
+		if err = r.SetParentTeam(ctx, tx, settings.TeamName, *settings.ParentTeamName); err != nil {
+			return nil, err
+		}
+	}
+
+	var parentTeamName sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT parent_team_name
+		FROM team
+		WHERE team_name = $1
+		`, settings.TeamName).Scan(&parentTeamName)
+	if err != nil {
+		log.Printf("query row error: %v", err)
+		return nil, fmt.Errorf("query row error: %w", err)
+	}
+	updated.ParentTeamName = &parentTeamName.String
+
+	after, err := r.ReadTeam(ctx, tx, settings.TeamName, false)
+	if err != nil {
+		return nil, err
+	}
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditTeamUpdateSettings,
+		entityType: model.AuditEntityTeam,
+		entityID:   settings.TeamName,
+		before:     before,
+		after:      after,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
 		log.Printf("commit transaction error: %v", err)
 		return nil, fmt.Errorf("commit transaction error: %w", err)
 	}
 
-	return &model.Team{
+	return &updated, nil
+}
+
+// SetParentTeam moves the team under parentTeamName, or makes it a root team when
+// parentTeamName is empty. A team can't become a descendant of itself.
+func (r *TeamPostgresRepository) SetParentTeam(ctx context.Context, tx *sql.Tx, teamName, parentTeamName string) error {
+	if parentTeamName != "" {
+		ok, err := r.TeamExists(ctx, tx, parentTeamName)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.Printf("parent team doesn't exist: %s", parentTeamName)
+			return model.NewNotFoundError()
+		}
+
+		ancestors, err := r.prs.GetAncestorTeams(ctx, tx, []string{parentTeamName})
+		if err != nil {
+			return err
+		}
+		if parentTeamName == teamName || slices.Contains(ancestors[parentTeamName], teamName) {
+			log.Printf("parent team %s is a descendant of %s", parentTeamName, teamName)
+			return model.NewInvalidFieldError("parent_team_name")
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		UPDATE team
+		SET parent_team_name = NULLIF($2, '')
+		WHERE team_name = $1
+		`, teamName, parentTeamName)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return fmt.Errorf("exec error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TeamPostgresRepository) SetFallbackTeams(ctx context.Context, tx *sql.Tx, teamName string, fallbackTeams []string) error {
+	for priority, fallbackTeam := range fallbackTeams {
+		ok, err := r.TeamExists(ctx, tx, fallbackTeam)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.Printf("fallback team doesn't exist: %s", fallbackTeam)
+			return model.NewNotFoundError()
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO team_fallback
+			(team_name, fallback_team_name, priority)
+			VALUES ($1, $2, $3)
+			`, teamName, fallbackTeam, priority)
+		if err != nil {
+			log.Printf("exec error: %v", err)
+			return fmt.Errorf("exec error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *TeamPostgresRepository) SetCodeOwners(ctx context.Context, owners model.CodeOwners) (*model.CodeOwners, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	ok, err := r.TeamExists(ctx, tx, owners.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Printf("team doesn't exist: %s", owners.TeamName)
+		return nil, model.NewNotFoundError()
+	}
+
+	beforeRules, err := r.GetCodeOwnerRules(ctx, tx, owners.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM code_owner_rule
+		WHERE team_name = $1
+		`, owners.TeamName)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return nil, fmt.Errorf("exec error: %w", err)
+	}
+
+	for position, rule := range owners.Rules {
+		for _, userID := range rule.Users {
+			var exists bool
+			err = tx.QueryRowContext(ctx, `
+				SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)
+				`, userID).Scan(&exists)
+			if err != nil {
+				log.Printf("query row error: %v", err)
+				return nil, fmt.Errorf("query row error: %w", err)
+			}
+			if !exists {
+				log.Printf("code owner doesn't exist: %s", userID)
+				return nil, model.NewNotFoundError()
+			}
+
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO code_owner_rule
+				(team_name, position, pattern, owner_user_id)
+				VALUES ($1, $2, $3, $4)
+				`, owners.TeamName, position, rule.Pattern, userID)
+			if err != nil {
+				log.Printf("exec error: %v", err)
+				return nil, fmt.Errorf("exec error: %w", err)
+			}
+		}
+
+		for _, teamName := range rule.Teams {
+			ok, err = r.TeamExists(ctx, tx, teamName)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				log.Printf("code owner team doesn't exist: %s", teamName)
+				return nil, model.NewNotFoundError()
+			}
+
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO code_owner_rule
+				(team_name, position, pattern, owner_team_name)
+				VALUES ($1, $2, $3, $4)
+				`, owners.TeamName, position, rule.Pattern, teamName)
+			if err != nil {
+				log.Printf("exec error: %v", err)
+				return nil, fmt.Errorf("exec error: %w", err)
+			}
+		}
+	}
+
+	rules, err := r.GetCodeOwnerRules(ctx, tx, owners.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	updated := &model.CodeOwners{
+		TeamName: owners.TeamName,
+		Rules:    rules,
+	}
+
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditTeamSetCodeOwners,
+		entityType: model.AuditEntityTeam,
+		entityID:   owners.TeamName,
+		before:     model.CodeOwners{TeamName: owners.TeamName, Rules: beforeRules},
+		after:      updated,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (r *TeamPostgresRepository) GetCodeOwners(ctx context.Context, teamName string) (*model.CodeOwners, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	ok, err := r.TeamExists(ctx, tx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Printf("team doesn't exist: %s", teamName)
+		return nil, model.NewNotFoundError()
+	}
+
+	rules, err := r.GetCodeOwnerRules(ctx, tx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+
+	return &model.CodeOwners{
 		TeamName: teamName,
-		Members:  members,
+		Rules:    rules,
 	}, nil
 }
+
+func (r *TeamPostgresRepository) GetCodeOwnerRules(ctx context.Context, tx *sql.Tx, teamName string) ([]model.CodeOwnerRule, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT position, pattern, owner_user_id, owner_team_name
+		FROM code_owner_rule
+		WHERE team_name = $1
+		ORDER BY position
+		`, teamName)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCodeOwnerRules(rows)
+}
+
+// scanCodeOwnerRules folds rows of (position, pattern, owner_user_id, owner_team_name),
+// ordered by position, into rules with all their owners.
+func scanCodeOwnerRules(rows *sql.Rows) ([]model.CodeOwnerRule, error) {
+	rules := []model.CodeOwnerRule{}
+	lastPosition := -1
+	for rows.Next() {
+		var position int
+		var pattern string
+		var ownerUserID, ownerTeamName sql.NullString
+		if err := rows.Scan(&position, &pattern, &ownerUserID, &ownerTeamName); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+
+		if position != lastPosition {
+			rules = append(rules, model.CodeOwnerRule{Pattern: pattern, Users: []string{}, Teams: []string{}})
+			lastPosition = position
+		}
+		rule := &rules[len(rules)-1]
+		if ownerUserID.Valid {
+			rule.Users = append(rule.Users, ownerUserID.String)
+		}
+		if ownerTeamName.Valid {
+			rule.Teams = append(rule.Teams, ownerTeamName.String)
+		}
+	}
+
+	return rules, nil
+}
+
+// DeactivateUsers deactivates team members and hands all their OPEN reviews over in one transaction.
+// Data is loaded with a fixed number of queries and new reviewers are picked in memory, so the cost
+// does not grow with the number of round trips per PR. Authors are never picked for their own PRs
+// and no PR gets more reviewers than its team requires.
+func (r *TeamPostgresRepository) DeactivateUsers(ctx context.Context, teamName string, userIDs []string, pick model.ReviewerPicker) ([]model.PRReassignment, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	ok, err := r.TeamExists(ctx, tx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Printf("team doesn't exist: %s", teamName)
+		return nil, model.NewNotFoundError()
+	}
+
+	before, err := r.ReadTeam(ctx, tx, teamName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET is_active = false
+		WHERE user_id = ANY($2)
+		AND EXISTS (SELECT 1 FROM team_member WHERE team_member.user_id = users.user_id AND team_name = $1)
+		`, teamName, userIDs)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return nil, fmt.Errorf("exec error: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("RowsAffected error: %v", err)
+		return nil, fmt.Errorf("RowsAffected error: %w", err)
+	}
+	if rowsAffected != int64(len(userIDs)) {
+		log.Printf("not all users are members of team %s", teamName)
+		return nil, model.NewNotFoundError()
+	}
+
+	after, err := r.ReadTeam(ctx, tx, teamName, false)
+	if err != nil {
+		return nil, err
+	}
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditTeamDeactivateUsers,
+		entityType: model.AuditEntityTeam,
+		entityID:   teamName,
+		before:     before,
+		after:      after,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	activityEntries := []outboxEntry{}
+	for _, member := range before.Members {
+		if member.IsActive && slices.Contains(userIDs, member.UserID) {
+			activityEntries = append(activityEntries, outboxEntry{
+				eventType: model.WebhookEventUserActivityChanged,
+				data:      model.UserActivityChange{UserID: member.UserID, IsActive: false},
+			})
+		}
+	}
+	if err = writeOutboxEvents(ctx, tx, activityEntries...); err != nil {
+		return nil, err
+	}
+
+	reassignments, authors, authorTeams, err := r.RemoveOpenReviews(ctx, tx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(reassignments) == 0 {
+		if err = tx.Commit(); err != nil {
+			log.Printf("commit transaction error: %v", err)
+			return nil, fmt.Errorf("commit transaction error: %w", err)
+		}
+		return reassignments, nil
+	}
+
+	prIDs := make([]string, 0, len(reassignments))
+	for _, reassignment := range reassignments {
+		prIDs = append(prIDs, reassignment.PullRequestID)
+	}
+	remaining, err := r.GetReviewersByPR(ctx, tx, prIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	pools, err := r.GetTeamPools(ctx, tx, authorTeams)
+	if err != nil {
+		return nil, err
+	}
+
+	type newReviewer struct {
+		pullRequestID string
+		userID        string
+		isFallback    bool
+	}
+	added := []newReviewer{}
+	now := time.Now()
+	for i, reassignment := range reassignments {
+		pool := pools.PoolFor(authorTeams[i], append([]string{authors[i]}, remaining[reassignment.PullRequestID]...), remaining[reassignment.PullRequestID])
+		needed := min(len(reassignment.RemovedReviewers), pool.ReviewersRequired-len(remaining[reassignment.PullRequestID]))
+
+		if needed > 0 {
+			for _, reviewer := range pick(pool, needed) {
+				reassignments[i].NewReviewers = append(reassignments[i].NewReviewers, reviewer)
+				added = append(added, newReviewer{
+					pullRequestID: reassignment.PullRequestID,
+					userID:        reviewer,
+					isFallback:    pool.IsFallback(reviewer),
+				})
+				pools.Assigned(reviewer, now)
+			}
+		}
+
+		switch {
+		case needed <= 0:
+			reassignments[i].Outcome = model.HandoverRemoved
+		case len(reassignments[i].NewReviewers) == needed:
+			reassignments[i].Outcome = model.HandoverReassigned
+		case len(reassignments[i].NewReviewers) == 0:
+			reassignments[i].Outcome = model.HandoverNoCandidate
+		default:
+			reassignments[i].Outcome = model.HandoverPartiallyReassigned
+		}
+	}
+
+	if len(added) > 0 {
+		addedPRIDs := make([]string, 0, len(added))
+		addedUserIDs := make([]string, 0, len(added))
+		addedFallbacks := make([]bool, 0, len(added))
+		for _, reviewer := range added {
+			addedPRIDs = append(addedPRIDs, reviewer.pullRequestID)
+			addedUserIDs = append(addedUserIDs, reviewer.userID)
+			addedFallbacks = append(addedFallbacks, reviewer.isFallback)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO reviewer_x_pr (pr_id, user_id, is_fallback)
+			SELECT * FROM UNNEST($1::VARCHAR[], $2::VARCHAR[], $3::BOOLEAN[])
+			`, addedPRIDs, addedUserIDs, addedFallbacks)
+		if err != nil {
+			log.Printf("exec error: %v", err)
+			return nil, fmt.Errorf("exec error: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO reviewer_assignment (pr_id, user_id, reason)
+			SELECT pr_id, user_id, $3 FROM UNNEST($1::VARCHAR[], $2::VARCHAR[]) AS a(pr_id, user_id)
+			`, addedPRIDs, addedUserIDs, model.AssignmentReasonDeactivation)
+		if err != nil {
+			log.Printf("exec error: %v", err)
+			return nil, fmt.Errorf("exec error: %w", err)
+		}
+	}
+
+	entries := make([]auditEntry, 0, len(reassignments))
+	reassignmentEntries := make([]outboxEntry, 0, len(reassignments))
+	for _, reassignment := range reassignments {
+		kept := remaining[reassignment.PullRequestID]
+		entries = append(entries, auditEntry{
+			action:     model.AuditPRReassign,
+			entityType: model.AuditEntityPullRequest,
+			entityID:   reassignment.PullRequestID,
+			before:     reviewersSnapshot{Reviewers: append(append([]string{}, kept...), reassignment.RemovedReviewers...)},
+			after:      reviewersSnapshot{Reviewers: append(append([]string{}, kept...), reassignment.NewReviewers...)},
+		})
+		reassignmentEntries = append(reassignmentEntries, reassignmentEntry(reassignment.PullRequestID,
+			reassignment.RemovedReviewers, reassignment.NewReviewers, model.AssignmentReasonDeactivation))
+	}
+	if err = writeAuditEvents(ctx, tx, entries...); err != nil {
+		return nil, err
+	}
+	if err = writeOutboxEvents(ctx, tx, reassignmentEntries...); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return reassignments, nil
+}
+
+// RemoveOpenReviews unassigns the users from all OPEN PRs, closing their assignment periods,
+// and returns one entry per affected PR together with the PR's author and team.
+func (r *TeamPostgresRepository) RemoveOpenReviews(ctx context.Context, tx *sql.Tx, userIDs []string) ([]model.PRReassignment, []string, []string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		DELETE FROM reviewer_x_pr AS rpr
+		USING pr
+		WHERE rpr.pr_id = pr.pr_id
+		AND pr.status = 'OPEN'
+		AND rpr.user_id = ANY($1)
+		RETURNING rpr.pr_id, rpr.user_id, pr.author_id, COALESCE(pr.team_name, ''), pr.created_at
+		`, userIDs)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, nil, nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	type removedReview struct {
+		pullRequestID string
+		userID        string
+		authorID      string
+		authorTeam    string
+		createdAt     time.Time
+	}
+	removed := []removedReview{}
+	for rows.Next() {
+		var review removedReview
+		if err = rows.Scan(&review.pullRequestID, &review.userID, &review.authorID, &review.authorTeam, &review.createdAt); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, nil, nil, fmt.Errorf("scan error: %w", err)
+		}
+		removed = append(removed, review)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, nil, nil, fmt.Errorf("rows error: %w", err)
+	}
+	rows.Close()
+
+	removedPRIDs := make([]string, 0, len(removed))
+	removedUserIDs := make([]string, 0, len(removed))
+	for _, review := range removed {
+		removedPRIDs = append(removedPRIDs, review.pullRequestID)
+		removedUserIDs = append(removedUserIDs, review.userID)
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE reviewer_assignment AS ra
+		SET unassigned_at = CURRENT_TIMESTAMP, unassign_reason = $3
+		FROM UNNEST($1::VARCHAR[], $2::VARCHAR[]) AS removed(pr_id, user_id)
+		WHERE ra.pr_id = removed.pr_id AND ra.user_id = removed.user_id AND ra.unassigned_at IS NULL
+		`, removedPRIDs, removedUserIDs, model.AssignmentReasonDeactivation)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return nil, nil, nil, fmt.Errorf("exec error: %w", err)
+	}
+
+	// Older PRs get the first pick of the remaining reviewers.
+	slices.SortFunc(removed, func(a, b removedReview) int {
+		if c := a.createdAt.Compare(b.createdAt); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.pullRequestID, b.pullRequestID); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.userID, b.userID)
+	})
+
+	reassignments := []model.PRReassignment{}
+	authors := []string{}
+	authorTeams := []string{}
+	for _, review := range removed {
+		last := len(reassignments) - 1
+		if last >= 0 && reassignments[last].PullRequestID == review.pullRequestID {
+			reassignments[last].RemovedReviewers = append(reassignments[last].RemovedReviewers, review.userID)
+			continue
+		}
+		reassignments = append(reassignments, model.PRReassignment{
+			PullRequestID:    review.pullRequestID,
+			RemovedReviewers: []string{review.userID},
+			NewReviewers:     []string{},
+		})
+		authors = append(authors, review.authorID)
+		authorTeams = append(authorTeams, review.authorTeam)
+	}
+
+	return reassignments, authors, authorTeams, nil
+}
+
+// GetReviewersByPR returns the current reviewers of every given PR.
+func (r *TeamPostgresRepository) GetReviewersByPR(ctx context.Context, tx *sql.Tx, prIDs []string) (map[string][]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT pr_id, user_id
+		FROM reviewer_x_pr
+		WHERE pr_id = ANY($1)
+		`, prIDs)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	reviewers := make(map[string][]string, len(prIDs))
+	for rows.Next() {
+		var prID, userID string
+		if err = rows.Scan(&prID, &userID); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		reviewers[prID] = append(reviewers[prID], userID)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return reviewers, nil
+}
+
+// GetTeamPools loads settings, fallback teams and active candidates of the given teams,
+// their fallback teams and their ancestor teams at once.
+func (r *TeamPostgresRepository) GetTeamPools(ctx context.Context, tx *sql.Tx, teamNames []string) (*teamPools, error) {
+	pools := &teamPools{
+		settings:   map[string]model.ReviewerPool{},
+		fallbacks:  map[string][]string{},
+		members:    map[string][]string{},
+		leads:      map[string][]string{},
+		candidates: map[string]*model.ReviewerCandidate{},
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT team_name, assignment_strategy, reviewers_required, require_lead_review
+		FROM team
+		WHERE team_name = ANY($1)
+		`, teamNames)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	leadTeams := []string{}
+	for rows.Next() {
+		var teamName string
+		var requireLeadReview bool
+		var pool model.ReviewerPool
+		if err = rows.Scan(&teamName, &pool.Strategy, &pool.ReviewersRequired, &requireLeadReview); err != nil {
+			rows.Close()
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		pools.settings[teamName] = pool
+		if requireLeadReview {
+			leadTeams = append(leadTeams, teamName)
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT team_name, user_id
+		FROM team_member
+		WHERE team_name = ANY($1) AND role = 'lead'
+		`, leadTeams)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	for rows.Next() {
+		var teamName, userID string
+		if err = rows.Scan(&teamName, &userID); err != nil {
+			rows.Close()
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		pools.leads[teamName] = append(pools.leads[teamName], userID)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT team_name, fallback_team_name
+		FROM team_fallback
+		WHERE team_name = ANY($1)
+		ORDER BY team_name, priority
+		`, teamNames)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	candidateTeams := slices.Clone(teamNames)
+	for rows.Next() {
+		var teamName, fallbackTeam string
+		if err = rows.Scan(&teamName, &fallbackTeam); err != nil {
+			rows.Close()
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		pools.fallbacks[teamName] = append(pools.fallbacks[teamName], fallbackTeam)
+		candidateTeams = append(candidateTeams, fallbackTeam)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	ancestors, err := r.prs.GetAncestorTeams(ctx, tx, teamNames)
+	if err != nil {
+		return nil, err
+	}
+	for teamName, teamAncestors := range ancestors {
+		for _, ancestor := range teamAncestors {
+			if !slices.Contains(pools.fallbacks[teamName], ancestor) {
+				pools.fallbacks[teamName] = append(pools.fallbacks[teamName], ancestor)
+				candidateTeams = append(candidateTeams, ancestor)
+			}
+		}
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT u.user_id, tm.team_name, u.review_weight, u.max_open_reviews, COUNT(pr.pr_id), MAX(rpr.assigned_at)
+		FROM team_member AS tm
+		JOIN users AS u ON u.user_id = tm.user_id
+		LEFT JOIN reviewer_x_pr AS rpr ON rpr.user_id = u.user_id
+		LEFT JOIN pr ON pr.pr_id = rpr.pr_id AND pr.status = 'OPEN'
+		WHERE tm.team_name = ANY($1)
+		AND u.is_active = true
+		AND NOT EXISTS (
+			SELECT 1 FROM user_absence AS a
+			WHERE a.user_id = u.user_id
+			AND a.absent_from <= CURRENT_TIMESTAMP AND a.absent_to > CURRENT_TIMESTAMP
+		)
+		GROUP BY u.user_id, tm.team_name
+		HAVING u.max_open_reviews IS NULL OR COUNT(pr.pr_id) < u.max_open_reviews
+		`, candidateTeams)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var teamName string
+		var candidate model.ReviewerCandidate
+		var maxOpenReviews sql.NullInt64
+		var lastAssignedAt sql.NullTime
+		if err = rows.Scan(&candidate.UserID, &teamName, &candidate.Weight, &maxOpenReviews, &candidate.OpenReviews, &lastAssignedAt); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		if maxOpenReviews.Valid {
+			limit := int(maxOpenReviews.Int64)
+			candidate.MaxOpenReviews = &limit
+		}
+		candidate.LastAssignedAt = lastAssignedAt.Time
+		pools.members[teamName] = append(pools.members[teamName], candidate.UserID)
+		pools.candidates[candidate.UserID] = &candidate
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return pools, nil
+}
+
+// teamPools is an in-memory snapshot of reviewer candidates used for bulk reassignment.
+// Candidate load is updated as reviewers are picked, so later PRs see earlier assignments.
+type teamPools struct {
+	settings   map[string]model.ReviewerPool
+	fallbacks  map[string][]string
+	members    map[string][]string
+	leads      map[string][]string // leads of teams that require a lead review
+	candidates map[string]*model.ReviewerCandidate
+}
+
+// PoolFor builds the reviewer pool of the team without the excluded users. A lead slot is
+// reserved when the team requires a lead review and none of the current reviewers is a lead.
+func (p *teamPools) PoolFor(teamName string, excludedIDs, reviewers []string) model.ReviewerPool {
+	pool := model.ReviewerPool{
+		Strategy:           model.StrategyLeastLoaded,
+		ReviewersRequired:  model.DefaultReviewersRequired,
+		FallbackCandidates: [][]model.ReviewerCandidate{},
+	}
+	settings, ok := p.settings[teamName]
+	if !ok {
+		return pool
+	}
+	pool.Strategy, pool.ReviewersRequired = settings.Strategy, settings.ReviewersRequired
+
+	pool.Candidates = p.teamCandidates(teamName, excludedIDs)
+	if leads, ok := p.leads[teamName]; ok && !slices.ContainsFunc(reviewers, func(userID string) bool {
+		return slices.Contains(leads, userID)
+	}) {
+		pool.LeadCandidates = slices.DeleteFunc(slices.Clone(pool.Candidates), func(candidate model.ReviewerCandidate) bool {
+			return !slices.Contains(leads, candidate.UserID)
+		})
+	}
+	for _, fallbackTeam := range p.fallbacks[teamName] {
+		pool.FallbackCandidates = append(pool.FallbackCandidates, p.teamCandidates(fallbackTeam, excludedIDs))
+	}
+	return pool
+}
+
+// Assigned records a new open review of the user.
+func (p *teamPools) Assigned(userID string, at time.Time) {
+	if candidate, ok := p.candidates[userID]; ok {
+		candidate.OpenReviews++
+		candidate.LastAssignedAt = at
+	}
+}
+
+func (p *teamPools) teamCandidates(teamName string, excludedIDs []string) []model.ReviewerCandidate {
+	candidates := []model.ReviewerCandidate{}
+	for _, userID := range p.members[teamName] {
+		candidate := p.candidates[userID]
+		if !candidate.AtCapacity() && !slices.Contains(excludedIDs, userID) {
+			candidates = append(candidates, *candidate)
+		}
+	}
+	return candidates
+}
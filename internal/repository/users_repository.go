@@ -3,25 +3,30 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
 
 	"github.com/karambo3a/avito_test_task/internal/model"
 )
 
 type UsersPostgresRepository struct {
-	db *sql.DB
+	db  *sql.DB
+	prs *PRPostgresRepository
 }
 
 func NewUsersPostgresRepository(db *sql.DB) *UsersPostgresRepository {
-	return &UsersPostgresRepository{db: db}
+	return &UsersPostgresRepository{db: db, prs: NewPRPostgresRepository(db)}
 }
 
-func (r *UsersPostgresRepository) SetUserIsActive(ctx context.Context, userID string, isActive bool) (*model.User, error) {
+// SetUserIsActive changes the user's activity. With reassign set, the OPEN reviews of a deactivated user
+// are handed over to other candidates in the same transaction.
+func (r *UsersPostgresRepository) SetUserIsActive(ctx context.Context, userID string, isActive, reassign bool, pick model.ReviewerPicker) (*model.User, []model.ReviewHandover, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		log.Printf("begin transaction error: %v", err)
-		return nil, fmt.Errorf("begin transaction error: %w", err)
+		return nil, nil, fmt.Errorf("begin transaction error: %w", err)
 	}
 	defer func() {
 		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
@@ -29,43 +34,87 @@ func (r *UsersPostgresRepository) SetUserIsActive(ctx context.Context, userID st
 		}
 	}()
 
-	result, err := tx.ExecContext(ctx, `
+	before, err := r.GetUser(ctx, tx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("user not found: %v", userID)
+			return nil, nil, model.NewNotFoundError()
+		}
+		return nil, nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
 		UPDATE users
 		SET is_active = $1
 		WHERE user_id = $2
 		`, isActive, userID)
-
 	if err != nil {
 		log.Printf("exec error: %v", err)
-		return nil, fmt.Errorf("exec error: %w", err)
+		return nil, nil, fmt.Errorf("exec error: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	handovers := []model.ReviewHandover{}
+	if !isActive && reassign {
+		handovers, err = r.prs.ReassignOpenReviews(ctx, tx, []string{userID}, "", model.AssignmentReasonDeactivation, pick)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	user, err := r.GetUser(ctx, tx, userID)
 	if err != nil {
-		log.Printf("RowsAffected error: %v", err)
-		return nil, fmt.Errorf("RowsAffected error: %w", err)
+		return nil, nil, err
 	}
-	if rowsAffected == 0 {
-		log.Printf("user not found: %v", userID)
-		return nil, model.NewNotFoundError()
+
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditUserSetIsActive,
+		entityType: model.AuditEntityUser,
+		entityID:   userID,
+		before:     before,
+		after:      user,
+	})
+	if err != nil {
+		return nil, nil, err
 	}
 
-	row := tx.QueryRowContext(ctx, `
-		SELECT user_id, username, team_name, is_active
-		FROM users
-		WHERE user_id = $1
-		`, userID)
+	if before.IsActive != user.IsActive {
+		err = writeOutboxEvents(ctx, tx, outboxEntry{
+			eventType: model.WebhookEventUserActivityChanged,
+			data:      model.UserActivityChange{UserID: userID, IsActive: user.IsActive},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return user, handovers, nil
+}
 
+// GetUser returns the user with the teams they belong to.
+func (r *UsersPostgresRepository) GetUser(ctx context.Context, tx *sql.Tx, userID string) (*model.User, error) {
 	var user model.User
-	if err := row.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive); err != nil {
+	err := tx.QueryRowContext(ctx, `
+		SELECT user_id, username, is_active
+		FROM users
+		WHERE user_id = $1
+		`, userID).Scan(&user.UserID, &user.Username, &user.IsActive)
+	if err != nil {
 		log.Printf("scan error: %v", err)
 		return nil, fmt.Errorf("scan error: %w", err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		log.Printf("commit transaction error: %v", err)
-		return nil, fmt.Errorf("commit transaction error: %w", err)
+	user.Teams, err = r.prs.GetUserTeams(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(user.Teams) == 1 {
+		user.TeamName = user.Teams[0]
 	}
+
 	return &user, nil
 }
 
@@ -106,6 +155,134 @@ func (r *UsersPostgresRepository) GetUserReview(ctx context.Context, userID stri
 	return prs, nil
 }
 
+// MoveUser moves the user from one of their teams to another. Without fromTeamName the user's only team
+// is left. With reassign set, the user's OPEN reviews of the old team's PRs are handed over to candidates
+// from that team, otherwise the user keeps them.
+func (r *UsersPostgresRepository) MoveUser(ctx context.Context, userID, fromTeamName, teamName string, reassign bool, pick model.ReviewerPicker) (*model.User, []model.ReviewHandover, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	var teamExists bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM team WHERE team_name = $1)
+		`, teamName).Scan(&teamExists)
+	if err != nil {
+		log.Printf("query row error: %v", err)
+		return nil, nil, fmt.Errorf("query row error: %w", err)
+	}
+	if !teamExists {
+		log.Printf("team not found: %v", teamName)
+		return nil, nil, model.NewNotFoundError()
+	}
+
+	var id string
+	err = tx.QueryRowContext(ctx, `
+		SELECT user_id
+		FROM users
+		WHERE user_id = $1
+		FOR UPDATE
+		`, userID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("user not found: %v", userID)
+			return nil, nil, model.NewNotFoundError()
+		}
+		log.Printf("query row error: %v", err)
+		return nil, nil, fmt.Errorf("query row error: %w", err)
+	}
+
+	before, err := r.GetUser(ctx, tx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	teams := before.Teams
+	switch {
+	case fromTeamName != "":
+		if !slices.Contains(teams, fromTeamName) {
+			log.Printf("user %s is not a member of team %s", userID, fromTeamName)
+			return nil, nil, model.NewNotFoundError()
+		}
+	case len(teams) > 1:
+		log.Printf("user %s belongs to several teams", userID)
+		return nil, nil, model.NewEmptyFieldError("from_team_name")
+	case len(teams) == 1:
+		fromTeamName = teams[0]
+	}
+
+	handovers := []model.ReviewHandover{}
+	if fromTeamName != teamName {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO team_member (team_name, user_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+			`, teamName, userID)
+		if err != nil {
+			log.Printf("exec error: %v", err)
+			return nil, nil, fmt.Errorf("exec error: %w", err)
+		}
+
+		if fromTeamName != "" {
+			_, err = tx.ExecContext(ctx, `
+				DELETE FROM team_member
+				WHERE team_name = $1 AND user_id = $2
+				`, fromTeamName, userID)
+			if err != nil {
+				log.Printf("exec error: %v", err)
+				return nil, nil, fmt.Errorf("exec error: %w", err)
+			}
+
+			if reassign {
+				handovers, err = r.prs.ReassignOpenReviews(ctx, tx, []string{userID}, fromTeamName, model.AssignmentReasonMembership, pick)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+	}
+
+	user, err := r.GetUser(ctx, tx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if fromTeamName != teamName {
+		err = writeAuditEvents(ctx, tx, auditEntry{
+			action:     model.AuditUserMoveTeam,
+			entityType: model.AuditEntityUser,
+			entityID:   userID,
+			before:     before,
+			after:      user,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var entries []outboxEntry
+		if fromTeamName != "" {
+			entries = append(entries, membersEntry(fromTeamName, nil, []string{userID}))
+		}
+		entries = append(entries, membersEntry(teamName, []string{userID}, nil))
+		if err = writeOutboxEvents(ctx, tx, entries...); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return user, handovers, nil
+}
+
 func (r *UsersPostgresRepository) UserExists(ctx context.Context, userID string) (bool, error) {
 	result := r.db.QueryRowContext(ctx, `
 		SELECT user_id
@@ -126,3 +303,93 @@ func (r *UsersPostgresRepository) UserExists(ctx context.Context, userID string)
 
 	return true, nil
 }
+
+// AddAbsence stores an unavailability window of the user. Adding the same window twice is a no-op.
+func (r *UsersPostgresRepository) AddAbsence(ctx context.Context, absence model.Absence) (*model.Absence, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)
+		`, absence.UserID).Scan(&exists)
+	if err != nil {
+		log.Printf("query row error: %v", err)
+		return nil, fmt.Errorf("query row error: %w", err)
+	}
+	if !exists {
+		log.Printf("user not found: %v", absence.UserID)
+		return nil, model.NewNotFoundError()
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO user_absence (user_id, absent_from, absent_to)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+		`, absence.UserID, absence.From, absence.To)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return nil, fmt.Errorf("exec error: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("RowsAffected error: %v", err)
+		return nil, fmt.Errorf("RowsAffected error: %w", err)
+	}
+	if rowsAffected > 0 {
+		err = writeAuditEvents(ctx, tx, auditEntry{
+			action:     model.AuditUserAddAbsence,
+			entityType: model.AuditEntityUser,
+			entityID:   absence.UserID,
+			after:      absence,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return &absence, nil
+}
+
+// GetTeamRoles returns the user's role in each of their teams.
+func (r *UsersPostgresRepository) GetTeamRoles(ctx context.Context, userID string) (map[string]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT team_name, role
+		FROM team_member
+		WHERE user_id = $1
+		`, userID)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	roles := map[string]string{}
+	for rows.Next() {
+		var teamName, role string
+		if err := rows.Scan(&teamName, &role); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		roles[teamName] = role
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return roles, nil
+}
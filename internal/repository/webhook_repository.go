@@ -0,0 +1,494 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/karambo3a/avito_test_task/internal/model"
+)
+
+type WebhookPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookPostgresRepository(db *sql.DB) *WebhookPostgresRepository {
+	return &WebhookPostgresRepository{db: db}
+}
+
+func (r *WebhookPostgresRepository) CreateSubscription(ctx context.Context, subscription model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO webhook_subscription
+		(subscription_id, url, secret)
+		VALUES ($1, $2, $3)
+		RETURNING created_at
+		`, subscription.SubscriptionID, subscription.URL, subscription.Secret).Scan(&subscription.CreatedAt)
+	if err != nil {
+		log.Printf("insert error: %v", err)
+		return nil, fmt.Errorf("insert error: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO webhook_subscription_event (subscription_id, event_type)
+		SELECT $1, UNNEST($2::VARCHAR[])
+		`, subscription.SubscriptionID, subscription.EventTypes)
+	if err != nil {
+		log.Printf("insert error: %v", err)
+		return nil, fmt.Errorf("insert error: %w", err)
+	}
+
+	// The secret is never written to the audit log.
+	audited := subscription
+	audited.Secret = ""
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditWebhookSubscribe,
+		entityType: model.AuditEntityWebhook,
+		entityID:   subscription.SubscriptionID,
+		after:      audited,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return &subscription, nil
+}
+
+// ListSubscriptions returns all subscriptions without their secrets.
+func (r *WebhookPostgresRepository) ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.subscription_id, s.url, s.created_at, e.event_type
+		FROM webhook_subscription s
+		JOIN webhook_subscription_event e ON e.subscription_id = s.subscription_id
+		ORDER BY s.created_at, s.subscription_id, e.event_type
+		`)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := []model.WebhookSubscription{}
+	for rows.Next() {
+		var subscription model.WebhookSubscription
+		var eventType string
+		if err = rows.Scan(&subscription.SubscriptionID, &subscription.URL, &subscription.CreatedAt, &eventType); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+
+		if n := len(subscriptions); n > 0 && subscriptions[n-1].SubscriptionID == subscription.SubscriptionID {
+			subscriptions[n-1].EventTypes = append(subscriptions[n-1].EventTypes, eventType)
+			continue
+		}
+		subscription.EventTypes = []string{eventType}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// DeleteSubscription removes the subscription together with its deliveries.
+func (r *WebhookPostgresRepository) DeleteSubscription(ctx context.Context, subscriptionID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	before, err := r.getSubscription(ctx, tx, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM webhook_subscription
+		WHERE subscription_id = $1
+		`, subscriptionID)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return fmt.Errorf("exec error: %w", err)
+	}
+
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditWebhookUnsubscribe,
+		entityType: model.AuditEntityWebhook,
+		entityID:   subscriptionID,
+		before:     before,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return fmt.Errorf("commit transaction error: %w", err)
+	}
+	return nil
+}
+
+// getSubscription locks the subscription and returns it without the secret.
+func (r *WebhookPostgresRepository) getSubscription(ctx context.Context, tx *sql.Tx, subscriptionID string) (*model.WebhookSubscription, error) {
+	subscription := model.WebhookSubscription{SubscriptionID: subscriptionID}
+	err := tx.QueryRowContext(ctx, `
+		SELECT url, created_at
+		FROM webhook_subscription
+		WHERE subscription_id = $1
+		FOR UPDATE
+		`, subscriptionID).Scan(&subscription.URL, &subscription.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("webhook subscription not found: %s", subscriptionID)
+			return nil, model.NewNotFoundError()
+		}
+		log.Printf("scan error: %v", err)
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT event_type
+		FROM webhook_subscription_event
+		WHERE subscription_id = $1
+		ORDER BY event_type
+		`, subscriptionID)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var eventType string
+		if err = rows.Scan(&eventType); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		subscription.EventTypes = append(subscription.EventTypes, eventType)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return &subscription, nil
+}
+
+// enqueueDeliveries queues the event for every subscription to its type and returns the number of deliveries.
+// Enqueuing the same event twice doesn't duplicate its deliveries.
+func enqueueDeliveries(ctx context.Context, tx *sql.Tx, eventID, eventType string, payload []byte) (int64, error) {
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO webhook_delivery (subscription_id, event_id, event_type, payload)
+		SELECT subscription_id, $1, $2, $3::JSONB
+		FROM webhook_subscription_event
+		WHERE event_type = $2
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+		`, eventID, eventType, string(payload))
+	if err != nil {
+		log.Printf("insert error: %v", err)
+		return 0, fmt.Errorf("insert error: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("rows affected error: %v", err)
+		return 0, fmt.Errorf("rows affected error: %w", err)
+	}
+	return count, nil
+}
+
+// ClaimDeliveries takes up to limit due deliveries and postpones them by lease, so that concurrent
+// dispatchers skip them while they are sent. A delivery whose attempt is never recorded is retried
+// after the lease.
+func (r *WebhookPostgresRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDispatch, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE webhook_delivery d
+		SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		FROM webhook_subscription s
+		WHERE s.subscription_id = d.subscription_id AND d.delivery_id IN (
+			SELECT delivery_id
+			FROM webhook_delivery
+			WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY next_attempt_at, delivery_id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING d.delivery_id, d.event_type, s.url, s.secret, d.payload::TEXT, d.attempt_count
+		`, limit, lease.Seconds())
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	dispatches := []model.WebhookDispatch{}
+	for rows.Next() {
+		var dispatch model.WebhookDispatch
+		var payload string
+		if err = rows.Scan(&dispatch.DeliveryID, &dispatch.EventType, &dispatch.URL, &dispatch.Secret, &payload, &dispatch.AttemptCount); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		dispatch.Payload = []byte(payload)
+		dispatches = append(dispatches, dispatch)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return dispatches, nil
+}
+
+// RecordAttempt stores the attempt and moves the delivery to the given status. A pending delivery
+// is retried after retryIn.
+func (r *WebhookPostgresRepository) RecordAttempt(ctx context.Context, deliveryID int64, attempt model.WebhookAttempt, status string, retryIn time.Duration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO webhook_attempt
+		(delivery_id, attempted_at, status_code, error, duration_ms)
+		VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, ''), $5)
+		`, deliveryID, attempt.AttemptedAt.UTC(), attempt.StatusCode, attempt.Error, attempt.DurationMS)
+	if err != nil {
+		log.Printf("insert error: %v", err)
+		return fmt.Errorf("insert error: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_delivery
+		SET attempt_count = attempt_count + 1,
+			status = $2::VARCHAR,
+			next_attempt_at = CASE WHEN $2::VARCHAR = 'pending' THEN CURRENT_TIMESTAMP + make_interval(secs => $3) END,
+			last_error = NULLIF($4, ''),
+			delivered_at = CASE WHEN $2::VARCHAR = 'delivered' THEN CURRENT_TIMESTAMP END
+		WHERE delivery_id = $1
+		`, deliveryID, status, retryIn.Seconds(), attempt.Error)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return fmt.Errorf("exec error: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return fmt.Errorf("commit transaction error: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the newest deliveries with all their attempts.
+func (r *WebhookPostgresRepository) ListDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter) ([]model.WebhookDelivery, error) {
+	conditions := []string{"TRUE"}
+	args := []any{}
+	addCondition := func(condition string, values ...any) {
+		placeholders := make([]any, 0, len(values))
+		for _, value := range values {
+			args = append(args, value)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+		conditions = append(conditions, fmt.Sprintf(condition, placeholders...))
+	}
+
+	if filter.SubscriptionID != "" {
+		addCondition("subscription_id = %s", filter.SubscriptionID)
+	}
+	if filter.Status != "" {
+		addCondition("status = %s", filter.Status)
+	}
+	args = append(args, filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM webhook_delivery
+		WHERE %s
+		ORDER BY delivery_id DESC
+		LIMIT $%d
+		`, deliveryColumns, strings.Join(conditions, " AND "), len(args)), args...)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []model.WebhookDelivery{}
+	positions := map[int64]int{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		positions[delivery.DeliveryID] = len(deliveries)
+		deliveries = append(deliveries, *delivery)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	rows.Close()
+
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	deliveryIDs := make([]int64, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		deliveryIDs = append(deliveryIDs, delivery.DeliveryID)
+	}
+	attemptRows, err := r.db.QueryContext(ctx, `
+		SELECT delivery_id, attempted_at, COALESCE(status_code, 0), COALESCE(error, ''), duration_ms
+		FROM webhook_attempt
+		WHERE delivery_id = ANY($1)
+		ORDER BY attempt_id
+		`, deliveryIDs)
+	if err != nil {
+		log.Printf("query error: %v", err)
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer attemptRows.Close()
+
+	for attemptRows.Next() {
+		var deliveryID int64
+		var attempt model.WebhookAttempt
+		if err = attemptRows.Scan(&deliveryID, &attempt.AttemptedAt, &attempt.StatusCode, &attempt.Error, &attempt.DurationMS); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		delivery := &deliveries[positions[deliveryID]]
+		delivery.Attempts = append(delivery.Attempts, attempt)
+	}
+	if err = attemptRows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// ReplayDelivery queues the delivery to be sent right away, whatever its status. Its attempt count
+// starts over, the recorded attempts are kept.
+func (r *WebhookPostgresRepository) ReplayDelivery(ctx context.Context, deliveryID int64) (*model.WebhookDelivery, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("begin transaction error: %v", err)
+		return nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer func() {
+		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("rollback transaction error: %v", err)
+		}
+	}()
+
+	before, err := r.getDelivery(ctx, tx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_delivery
+		SET status = 'pending',
+			attempt_count = 0,
+			next_attempt_at = CURRENT_TIMESTAMP,
+			delivered_at = NULL
+		WHERE delivery_id = $1
+		`, deliveryID)
+	if err != nil {
+		log.Printf("exec error: %v", err)
+		return nil, fmt.Errorf("exec error: %w", err)
+	}
+
+	delivery, err := r.getDelivery(ctx, tx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	err = writeAuditEvents(ctx, tx, auditEntry{
+		action:     model.AuditWebhookReplay,
+		entityType: model.AuditEntityDelivery,
+		entityID:   fmt.Sprint(deliveryID),
+		before:     before,
+		after:      delivery,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("commit transaction error: %v", err)
+		return nil, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return delivery, nil
+}
+
+const deliveryColumns = `delivery_id, subscription_id, event_id, event_type, status, attempt_count,
+	next_attempt_at, COALESCE(last_error, ''), created_at, delivered_at`
+
+func scanDelivery(row interface{ Scan(dest ...any) error }) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	var nextAttemptAt, deliveredAt sql.NullTime
+	err := row.Scan(&delivery.DeliveryID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.Status,
+		&delivery.AttemptCount, &nextAttemptAt, &delivery.LastError, &delivery.CreatedAt, &deliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	if nextAttemptAt.Valid {
+		delivery.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	delivery.Attempts = []model.WebhookAttempt{}
+	return &delivery, nil
+}
+
+// getDelivery locks the delivery and returns it without its attempts.
+func (r *WebhookPostgresRepository) getDelivery(ctx context.Context, tx *sql.Tx, deliveryID int64) (*model.WebhookDelivery, error) {
+	delivery, err := scanDelivery(tx.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM webhook_delivery
+		WHERE delivery_id = $1
+		FOR UPDATE
+		`, deliveryColumns), deliveryID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("webhook delivery not found: %d", deliveryID)
+			return nil, model.NewNotFoundError()
+		}
+		log.Printf("scan error: %v", err)
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+	return delivery, nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/karambo3a/avito_test_task/internal/model"
+	"github.com/karambo3a/avito_test_task/internal/repository"
+)
+
+type AuditService struct {
+	repository *repository.Repository
+}
+
+func NewAuditService(repo *repository.Repository) *AuditService {
+	return &AuditService{repository: repo}
+}
+
+const (
+	defaultAuditListLimit = 50
+	maxAuditListLimit     = 500
+)
+
+func (s *AuditService) ListAuditEvents(ctx context.Context, query model.AuditListQuery) (*model.AuditListPage, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	filter := model.AuditListFilter{
+		Actor:      query.Actor,
+		Action:     query.Action,
+		EntityType: query.EntityType,
+		EntityID:   query.EntityID,
+		Limit:      defaultAuditListLimit,
+	}
+
+	switch query.EntityType {
+	case "", model.AuditEntityTeam, model.AuditEntityUser, model.AuditEntityPullRequest, model.AuditEntityToken,
+		model.AuditEntityWebhook, model.AuditEntityDelivery:
+	default:
+		return nil, model.NewInvalidFieldError("entity_type")
+	}
+
+	if query.Limit != "" {
+		limit, err := strconv.Atoi(query.Limit)
+		if err != nil || limit <= 0 || limit > maxAuditListLimit {
+			return nil, model.NewInvalidFieldError("limit")
+		}
+		filter.Limit = limit
+	}
+
+	for _, param := range []struct {
+		field string
+		value string
+		dst   **time.Time
+	}{
+		{"from", query.From, &filter.From},
+		{"to", query.To, &filter.To},
+	} {
+		if param.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, param.value)
+		if err != nil {
+			return nil, model.NewInvalidFieldError(param.field)
+		}
+		t = t.UTC()
+		*param.dst = &t
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeAuditCursor(query.Cursor)
+		if err != nil {
+			return nil, model.NewInvalidFieldError("cursor")
+		}
+		filter.After = cursor
+	}
+
+	events, next, err := s.repository.ListAuditEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.AuditListPage{Events: events}
+	if next != nil {
+		page.NextCursor = encodeAuditCursor(*next)
+	}
+	return page, nil
+}
+
+// encodeAuditCursor makes an opaque page token from the position of the last listed event.
+func encodeAuditCursor(cursor model.AuditCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeAuditCursor(token string) (*model.AuditCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor model.AuditCursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
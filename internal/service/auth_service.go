@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/karambo3a/avito_test_task/internal/model"
+	"github.com/karambo3a/avito_test_task/internal/repository"
+)
+
+// bootstrapServiceAccount is the identity of callers using the admin token from the configuration.
+const bootstrapServiceAccount = "bootstrap"
+
+type AuthService struct {
+	repository *repository.Repository
+	adminToken string
+}
+
+// NewAuthService creates the service. A non-empty adminToken is accepted as an admin
+// service account token, so that the first tokens can be issued.
+func NewAuthService(r *repository.Repository, adminToken string) *AuthService {
+	return &AuthService{repository: r, adminToken: adminToken}
+}
+
+func (s *AuthService) Authenticate(ctx context.Context, secret string) (*model.Identity, error) {
+	if secret == "" {
+		return nil, model.NewUnauthorizedError()
+	}
+	if s.adminToken != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(s.adminToken)) == 1 {
+		return &model.Identity{ServiceAccount: bootstrapServiceAccount, IsAdmin: true}, nil
+	}
+	return s.repository.GetIdentity(ctx, hashToken(secret))
+}
+
+func (s *AuthService) IssueToken(ctx context.Context, token model.APIToken) (*model.IssuedToken, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	switch {
+	case token.UserID == "" && token.ServiceAccount == "":
+		return nil, model.NewEmptyFieldError("user_id")
+	case token.UserID != "" && token.ServiceAccount != "":
+		return nil, model.NewInvalidFieldError("service_account")
+	}
+
+	tokenID, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+
+	token.TokenID = tokenID
+	created, err := s.repository.CreateToken(ctx, token, hashToken(secret))
+	if err != nil {
+		return nil, err
+	}
+	return &model.IssuedToken{Token: *created, Secret: secret}, nil
+}
+
+func (s *AuthService) RevokeToken(ctx context.Context, tokenID string) (*model.APIToken, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if tokenID == "" {
+		return nil, model.NewEmptyFieldError("token_id")
+	}
+	return s.repository.RevokeToken(ctx, tokenID)
+}
+
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("random read error: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
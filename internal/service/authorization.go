@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+
+	"github.com/karambo3a/avito_test_task/internal/model"
+	"github.com/karambo3a/avito_test_task/internal/repository"
+)
+
+// caller returns the identity stored in the context by the authentication middleware.
+// Requests without an identity are not allowed to do anything.
+func caller(ctx context.Context) (*model.Identity, error) {
+	identity, ok := model.IdentityFromContext(ctx)
+	if !ok {
+		return nil, model.NewForbiddenError()
+	}
+	return identity, nil
+}
+
+func requireAdmin(ctx context.Context) error {
+	identity, err := caller(ctx)
+	if err != nil {
+		return err
+	}
+	if !identity.IsAdmin {
+		return model.NewForbiddenError()
+	}
+	return nil
+}
+
+// requireUserOrAdmin allows the given user themselves and admins.
+func requireUserOrAdmin(ctx context.Context, userID string) error {
+	identity, err := caller(ctx)
+	if err != nil {
+		return err
+	}
+	if !identity.IsAdmin && (identity.UserID == "" || identity.UserID != userID) {
+		return model.NewForbiddenError()
+	}
+	return nil
+}
+
+// requireTeamLead allows admins and callers who lead every one of the given teams.
+func requireTeamLead(ctx context.Context, r *repository.Repository, teamNames ...string) error {
+	identity, err := caller(ctx)
+	if err != nil {
+		return err
+	}
+	if identity.IsAdmin {
+		return nil
+	}
+	if identity.UserID == "" {
+		return model.NewForbiddenError()
+	}
+
+	roles, err := r.GetTeamRoles(ctx, identity.UserID)
+	if err != nil {
+		return err
+	}
+	for _, teamName := range teamNames {
+		if roles[teamName] != model.RoleLead && roles[teamName] != model.RoleAdmin {
+			return model.NewForbiddenError()
+		}
+	}
+	return nil
+}
+
+// requireUserLead allows the user themselves, admins and leads of any team the user belongs to.
+func requireUserLead(ctx context.Context, r *repository.Repository, userID string) error {
+	if err := requireUserOrAdmin(ctx, userID); err == nil {
+		return nil
+	}
+
+	identity, err := caller(ctx)
+	if err != nil {
+		return err
+	}
+	if identity.UserID == "" {
+		return model.NewForbiddenError()
+	}
+
+	userRoles, err := r.GetTeamRoles(ctx, userID)
+	if err != nil {
+		return err
+	}
+	callerRoles, err := r.GetTeamRoles(ctx, identity.UserID)
+	if err != nil {
+		return err
+	}
+	for teamName := range userRoles {
+		if role := callerRoles[teamName]; role == model.RoleLead || role == model.RoleAdmin {
+			return nil
+		}
+	}
+	return model.NewForbiddenError()
+}
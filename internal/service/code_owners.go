@@ -0,0 +1,85 @@
+package service
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/karambo3a/avito_test_task/internal/model"
+)
+
+// matchCodeOwners returns the users and teams owning the changed files.
+// As in CODEOWNERS, the last matching rule wins for every file.
+func matchCodeOwners(rules []model.CodeOwnerRule, changedFiles []string) ([]string, []string) {
+	ownerIDs := []string{}
+	ownerTeams := []string{}
+	for _, file := range changedFiles {
+		for i := len(rules) - 1; i >= 0; i-- {
+			if !matchOwnerPattern(rules[i].Pattern, file) {
+				continue
+			}
+			for _, userID := range rules[i].Users {
+				if !slices.Contains(ownerIDs, userID) {
+					ownerIDs = append(ownerIDs, userID)
+				}
+			}
+			for _, teamName := range rules[i].Teams {
+				if !slices.Contains(ownerTeams, teamName) {
+					ownerTeams = append(ownerTeams, teamName)
+				}
+			}
+			break
+		}
+	}
+	return ownerIDs, ownerTeams
+}
+
+// matchOwnerPattern matches a file against a gitignore-like pattern: "*" matches within
+// a path segment, "**" matches any number of segments, patterns without an inner slash
+// match at any depth and a matching directory covers every file inside it.
+func matchOwnerPattern(pattern, file string) bool {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	segments := strings.Split(strings.Trim(pattern, "/"), "/")
+	if !anchored {
+		segments = append([]string{"**"}, segments...)
+	}
+	segments = append(segments, "**")
+
+	return matchSegments(segments, strings.Split(strings.TrimPrefix(file, "/"), "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], name[1:])
+}
+
+func validateCodeOwnerRule(rule model.CodeOwnerRule) error {
+	if strings.Trim(rule.Pattern, "/") == "" {
+		return model.NewEmptyFieldError("pattern")
+	}
+	for _, segment := range strings.Split(strings.Trim(rule.Pattern, "/"), "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return model.NewInvalidFieldError("pattern")
+		}
+	}
+	if len(rule.Users) == 0 && len(rule.Teams) == 0 {
+		return model.NewEmptyFieldError("owners")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/karambo3a/avito_test_task/internal/model"
+	"github.com/karambo3a/avito_test_task/internal/repository"
+)
+
+const (
+	outboxBatchSize    = 100
+	outboxPollInterval = time.Second
+)
+
+// OutboxDispatcher turns the events written to the outbox together with the changes into webhook deliveries.
+// An event is taken again until its deliveries are queued, so it's published at least once.
+type OutboxDispatcher struct {
+	repository *repository.Repository
+	webhooks   *WebhookDispatcher
+	wake       chan struct{}
+}
+
+func NewOutboxDispatcher(r *repository.Repository, webhooks *WebhookDispatcher) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		repository: r,
+		webhooks:   webhooks,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// Notify wakes the dispatcher up after a change is committed, so its events don't wait for the next poll.
+func (d *OutboxDispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run drains the outbox until ctx is canceled. A batch being drained is finished or rolled back before it returns.
+func (d *OutboxDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.drain(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *OutboxDispatcher) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		published, deliveries, err := d.repository.DrainOutbox(ctx, outboxBatchSize, encodeOutboxEvent)
+		if err != nil {
+			log.Printf("outbox drain error: %v", err)
+			return
+		}
+		if deliveries > 0 {
+			d.webhooks.Notify()
+		}
+		if published < outboxBatchSize {
+			return
+		}
+	}
+}
+
+// encodeOutboxEvent builds the payload sent to the subscribers. The event ID stays the same
+// if the event is published again, so subscribers can skip duplicates.
+func encodeOutboxEvent(event model.OutboxEvent) ([]byte, error) {
+	return json.Marshal(model.WebhookEvent{
+		EventID:    event.EventID(),
+		EventType:  event.EventType,
+		OccurredAt: event.CreatedAt.UTC(),
+		Data:       event.Data,
+	})
+}
@@ -2,6 +2,11 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/karambo3a/avito_test_task/internal/model"
 	"github.com/karambo3a/avito_test_task/internal/repository"
@@ -9,29 +14,169 @@ import (
 
 type PullRequestService struct {
 	repository *repository.Repository
+	events     *OutboxDispatcher
 }
 
-func NewPullRequestService(r *repository.Repository) *PullRequestService {
-	return &PullRequestService{repository: r}
+// prTransition is an edge of the PR state machine: the statuses an action may start from and the resulting status.
+type prTransition struct {
+	from []string
+	to   string
 }
 
-func (s *PullRequestService) CreatePR(ctx context.Context, pullRequestID, pullRequestName, authorID string) (*model.PullRequest, error) {
+var (
+	transitionMarkReady = prTransition{from: []string{model.PRStatusDraft}, to: model.PRStatusOpen}
+	transitionMerge     = prTransition{from: []string{model.PRStatusOpen}, to: model.PRStatusMerged}
+	transitionClose     = prTransition{from: []string{model.PRStatusDraft, model.PRStatusOpen}, to: model.PRStatusClosed}
+	transitionReopen    = prTransition{from: []string{model.PRStatusClosed}, to: model.PRStatusOpen}
+)
+
+func NewPullRequestService(r *repository.Repository, events *OutboxDispatcher) *PullRequestService {
+	return &PullRequestService{repository: r, events: events}
+}
+
+func (s *PullRequestService) CreatePR(ctx context.Context, input model.CreatePRInput) (*model.PullRequest, error) {
 	switch {
-	case pullRequestID == "":
+	case input.PullRequestID == "":
 		return nil, model.NewEmptyFieldError("pull_request_id")
-	case pullRequestName == "":
+	case input.PullRequestName == "":
 		return nil, model.NewEmptyFieldError("pull_request_name")
-	case authorID == "":
+	case input.AuthorID == "":
 		return nil, model.NewEmptyFieldError("author_id")
 	}
-	return s.repository.CreatePR(ctx, pullRequestID, pullRequestName, authorID)
+
+	teamName, err := s.repository.ResolvePRTeam(ctx, input.AuthorID, input.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	input.TeamName = teamName
+
+	if len(input.ChangedFiles) > 0 && !input.Draft && input.TeamName != "" {
+		rules, err := s.repository.GetCodeOwnerRules(ctx, input.TeamName)
+		if err != nil {
+			return nil, err
+		}
+		input.OwnerIDs, input.OwnerTeams = matchCodeOwners(rules, input.ChangedFiles)
+	}
+
+	pr, err := s.repository.CreatePR(ctx, input, pickReviewers)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Notify()
+	return pr, nil
+}
+
+func (s *PullRequestService) MergePR(ctx context.Context, pullRequestID string, force bool) (*model.PullRequest, error) {
+	if pullRequestID == "" {
+		return nil, model.NewEmptyFieldError("pull_request_id")
+	}
+
+	pr, err := s.repository.GetPullRequest(ctx, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+	if err = requireUserOrAdmin(ctx, pr.AuthorID); err != nil {
+		return nil, err
+	}
+	if pr.Status != model.PRStatusMerged && !slices.Contains(transitionMerge.from, pr.Status) {
+		return nil, model.NewInvalidTransitionError(pr.Status, transitionMerge.to)
+	}
+
+	// Only admins may merge past the approval check, the admin is stored with the PR.
+	var forcedBy string
+	if force {
+		if err = requireAdmin(ctx); err != nil {
+			return nil, err
+		}
+		identity, err := caller(ctx)
+		if err != nil {
+			return nil, err
+		}
+		forcedBy = identity.Actor()
+	}
+
+	merged, err := s.repository.MergePR(ctx, pullRequestID, forcedBy)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Notify()
+	return merged, nil
 }
 
-func (s *PullRequestService) MergePR(ctx context.Context, pullRequestID string) (*model.PullRequest, error) {
+func (s *PullRequestService) GetPR(ctx context.Context, pullRequestID string) (*model.PullRequest, error) {
 	if pullRequestID == "" {
 		return nil, model.NewEmptyFieldError("pull_request_id")
 	}
-	return s.repository.MergePR(ctx, pullRequestID)
+	return s.repository.GetPullRequest(ctx, pullRequestID)
+}
+
+func (s *PullRequestService) GetPRHistory(ctx context.Context, pullRequestID string) (*model.PRHistory, error) {
+	if pullRequestID == "" {
+		return nil, model.NewEmptyFieldError("pull_request_id")
+	}
+	return s.repository.GetPRHistory(ctx, pullRequestID)
+}
+
+func (s *PullRequestService) MarkReady(ctx context.Context, pullRequestID string) (*model.PullRequest, error) {
+	pr, err := s.checkTransition(ctx, pullRequestID, transitionMarkReady)
+	if err != nil {
+		return nil, err
+	}
+	if err = requireUserOrAdmin(ctx, pr.AuthorID); err != nil {
+		return nil, err
+	}
+
+	files, err := s.repository.GetChangedFiles(ctx, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ownerIDs, ownerTeams []string
+	if len(files) > 0 && pr.TeamName != "" {
+		rules, err := s.repository.GetCodeOwnerRules(ctx, pr.TeamName)
+		if err != nil {
+			return nil, err
+		}
+		ownerIDs, ownerTeams = matchCodeOwners(rules, files)
+	}
+
+	pr, err = s.repository.MarkReady(ctx, pullRequestID, ownerIDs, ownerTeams, pickReviewers)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Notify()
+	return pr, nil
+}
+
+func (s *PullRequestService) ClosePR(ctx context.Context, pullRequestID string) (*model.PullRequest, error) {
+	pr, err := s.checkTransition(ctx, pullRequestID, transitionClose)
+	if err != nil {
+		return nil, err
+	}
+	if err = requireUserOrAdmin(ctx, pr.AuthorID); err != nil {
+		return nil, err
+	}
+	return s.repository.UpdateStatus(ctx, pullRequestID, pr.Status, transitionClose.to)
+}
+
+func (s *PullRequestService) ReopenPR(ctx context.Context, pullRequestID string) (*model.PullRequest, error) {
+	pr, err := s.checkTransition(ctx, pullRequestID, transitionReopen)
+	if err != nil {
+		return nil, err
+	}
+	if err = requireUserOrAdmin(ctx, pr.AuthorID); err != nil {
+		return nil, err
+	}
+
+	// A closed draft becomes a draft again, so that reviewers are assigned by markReady.
+	status, err := s.repository.GetStatusBeforeClose(ctx, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(transitionClose.from, status) {
+		status = transitionReopen.to
+	}
+	return s.repository.UpdateStatus(ctx, pullRequestID, pr.Status, status)
 }
 
 func (s *PullRequestService) ReassignPR(ctx context.Context, pullRequestID, oldReviewerID string) (*model.PullRequest, string, error) {
@@ -41,5 +186,139 @@ func (s *PullRequestService) ReassignPR(ctx context.Context, pullRequestID, oldR
 	case oldReviewerID == "":
 		return nil, "", model.NewEmptyFieldError("old_reviewer_id")
 	}
-	return s.repository.ReassignPR(ctx, pullRequestID, oldReviewerID)
+	if err := requireUserOrAdmin(ctx, oldReviewerID); err != nil {
+		return nil, "", err
+	}
+
+	pr, newReviewerID, err := s.repository.ReassignPR(ctx, pullRequestID, oldReviewerID, pickReviewers)
+	if err != nil {
+		return nil, "", err
+	}
+	s.events.Notify()
+	return pr, newReviewerID, nil
+}
+
+func (s *PullRequestService) SubmitReview(ctx context.Context, pullRequestID, reviewerID, state string) (*model.PullRequest, error) {
+	switch {
+	case pullRequestID == "":
+		return nil, model.NewEmptyFieldError("pull_request_id")
+	case reviewerID == "":
+		return nil, model.NewEmptyFieldError("reviewer_id")
+	case state == "":
+		return nil, model.NewEmptyFieldError("state")
+	}
+
+	switch state {
+	case model.ReviewStateApproved, model.ReviewStateChangesRequested, model.ReviewStateCommented:
+	default:
+		return nil, model.NewInvalidFieldError("state")
+	}
+	if err := requireUserOrAdmin(ctx, reviewerID); err != nil {
+		return nil, err
+	}
+	return s.repository.SubmitReview(ctx, pullRequestID, reviewerID, state)
+}
+
+const (
+	defaultPRListLimit = 20
+	maxPRListLimit     = 100
+)
+
+func (s *PullRequestService) ListPRs(ctx context.Context, query model.PRListQuery) (*model.PRListPage, error) {
+	filter := model.PRListFilter{
+		Status:     query.Status,
+		AuthorID:   query.AuthorID,
+		ReviewerID: query.ReviewerID,
+		TeamName:   query.TeamName,
+		Limit:      defaultPRListLimit,
+	}
+
+	switch query.Status {
+	case "", model.PRStatusDraft, model.PRStatusOpen, model.PRStatusMerged, model.PRStatusClosed:
+	default:
+		return nil, model.NewInvalidFieldError("status")
+	}
+
+	if query.Limit != "" {
+		limit, err := strconv.Atoi(query.Limit)
+		if err != nil || limit <= 0 || limit > maxPRListLimit {
+			return nil, model.NewInvalidFieldError("limit")
+		}
+		filter.Limit = limit
+	}
+
+	for _, param := range []struct {
+		field string
+		value string
+		dst   **time.Time
+	}{
+		{"created_from", query.CreatedFrom, &filter.CreatedFrom},
+		{"created_to", query.CreatedTo, &filter.CreatedTo},
+		{"merged_from", query.MergedFrom, &filter.MergedFrom},
+		{"merged_to", query.MergedTo, &filter.MergedTo},
+	} {
+		if param.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, param.value)
+		if err != nil {
+			return nil, model.NewInvalidFieldError(param.field)
+		}
+		t = t.UTC()
+		*param.dst = &t
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodePRCursor(query.Cursor)
+		if err != nil {
+			return nil, model.NewInvalidFieldError("cursor")
+		}
+		filter.After = cursor
+	}
+
+	prs, next, err := s.repository.ListPRs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.PRListPage{PullRequests: prs}
+	if next != nil {
+		page.NextCursor = encodePRCursor(*next)
+	}
+	return page, nil
+}
+
+// checkTransition returns the current PR if the transition may start from its status.
+func (s *PullRequestService) checkTransition(ctx context.Context, pullRequestID string, transition prTransition) (*model.PullRequest, error) {
+	if pullRequestID == "" {
+		return nil, model.NewEmptyFieldError("pull_request_id")
+	}
+
+	pr, err := s.repository.GetPullRequest(ctx, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(transition.from, pr.Status) {
+		return nil, model.NewInvalidTransitionError(pr.Status, transition.to)
+	}
+	return pr, nil
+}
+
+// encodePRCursor makes an opaque page token from the position of the last listed PR.
+func encodePRCursor(cursor model.PRCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePRCursor(token string) (*model.PRCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor model.PRCursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
 }
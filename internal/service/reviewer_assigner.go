@@ -0,0 +1,138 @@
+package service
+
+import (
+	"cmp"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/karambo3a/avito_test_task/internal/model"
+)
+
+type ReviewerAssigner interface {
+	Assign(candidates []model.ReviewerCandidate, count int) []string
+}
+
+// RandomAssigner picks reviewers uniformly at random.
+type RandomAssigner struct{}
+
+// LeastLoadedAssigner prefers reviewers with the fewest open reviews, ties are broken randomly.
+type LeastLoadedAssigner struct{}
+
+// RoundRobinAssigner prefers reviewers who were assigned the longest time ago.
+type RoundRobinAssigner struct{}
+
+// WeightedAssigner picks reviewers randomly with probability proportional to their review weight.
+type WeightedAssigner struct{}
+
+func NewReviewerAssigner(strategy string) ReviewerAssigner {
+	switch strategy {
+	case model.StrategyRandom:
+		return RandomAssigner{}
+	case model.StrategyRoundRobin:
+		return RoundRobinAssigner{}
+	case model.StrategyWeighted:
+		return WeightedAssigner{}
+	default:
+		return LeastLoadedAssigner{}
+	}
+}
+
+func IsValidStrategy(strategy string) bool {
+	switch strategy {
+	case model.StrategyRandom, model.StrategyLeastLoaded, model.StrategyRoundRobin, model.StrategyWeighted:
+		return true
+	}
+	return false
+}
+
+// pickReviewers reserves a slot for a team lead when the pool requires one, fills the rest
+// with code owners first, then with the author's team and finally with fallback and ancestor
+// teams, using the team's strategy at every step.
+func pickReviewers(pool model.ReviewerPool, count int) []string {
+	assigner := NewReviewerAssigner(pool.Strategy)
+	groups := append([][]model.ReviewerCandidate{pool.OwnerCandidates, pool.Candidates}, pool.FallbackCandidates...)
+
+	reviewers := []string{}
+	if count > 0 && len(pool.LeadCandidates) > 0 {
+		reviewers = append(reviewers, assigner.Assign(pool.LeadCandidates, 1)...)
+	}
+	for _, candidates := range groups {
+		if len(reviewers) >= count {
+			break
+		}
+		candidates = slices.DeleteFunc(slices.Clone(candidates), func(candidate model.ReviewerCandidate) bool {
+			return slices.Contains(reviewers, candidate.UserID)
+		})
+		reviewers = append(reviewers, assigner.Assign(candidates, count-len(reviewers))...)
+	}
+	return reviewers
+}
+
+func (RandomAssigner) Assign(candidates []model.ReviewerCandidate, count int) []string {
+	shuffled := shuffleCandidates(candidates)
+	return firstReviewers(shuffled, count)
+}
+
+func (LeastLoadedAssigner) Assign(candidates []model.ReviewerCandidate, count int) []string {
+	shuffled := shuffleCandidates(candidates)
+	slices.SortStableFunc(shuffled, func(a, b model.ReviewerCandidate) int {
+		return cmp.Compare(a.OpenReviews, b.OpenReviews)
+	})
+	return firstReviewers(shuffled, count)
+}
+
+func (RoundRobinAssigner) Assign(candidates []model.ReviewerCandidate, count int) []string {
+	sorted := slices.Clone(candidates)
+	slices.SortFunc(sorted, func(a, b model.ReviewerCandidate) int {
+		if c := a.LastAssignedAt.Compare(b.LastAssignedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.UserID, b.UserID)
+	})
+	return firstReviewers(sorted, count)
+}
+
+func (WeightedAssigner) Assign(candidates []model.ReviewerCandidate, count int) []string {
+	type weightedKey struct {
+		candidate model.ReviewerCandidate
+		key       float64
+	}
+
+	keys := make([]weightedKey, 0, len(candidates))
+	for _, candidate := range candidates {
+		weight := max(candidate.Weight, 1)
+		keys = append(keys, weightedKey{
+			candidate: candidate,
+			key:       math.Pow(rand.Float64(), 1/float64(weight)),
+		})
+	}
+	slices.SortFunc(keys, func(a, b weightedKey) int {
+		return cmp.Compare(b.key, a.key)
+	})
+
+	sorted := make([]model.ReviewerCandidate, 0, len(keys))
+	for _, k := range keys {
+		sorted = append(sorted, k.candidate)
+	}
+	return firstReviewers(sorted, count)
+}
+
+func shuffleCandidates(candidates []model.ReviewerCandidate) []model.ReviewerCandidate {
+	shuffled := slices.Clone(candidates)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
+
+func firstReviewers(candidates []model.ReviewerCandidate, count int) []string {
+	reviewers := []string{}
+	for _, candidate := range candidates {
+		if len(reviewers) == count {
+			break
+		}
+		reviewers = append(reviewers, candidate.UserID)
+	}
+	return reviewers
+}
@@ -9,18 +9,35 @@ import (
 
 type Team interface {
 	AddTeam(ctx context.Context, team model.Team) (*model.Team, error)
-	GetTeam(ctx context.Context, teamName string) (*model.Team, error)
+	GetTeam(ctx context.Context, teamName string, withSubTeams bool) (*model.Team, error)
+	UpdateTeamSettings(ctx context.Context, settings model.TeamSettings) (*model.TeamSettings, error)
+	SetCodeOwners(ctx context.Context, owners model.CodeOwners) (*model.CodeOwners, error)
+	GetCodeOwners(ctx context.Context, teamName string) (*model.CodeOwners, error)
+	AddMembers(ctx context.Context, teamName string, members []model.TeamMember) (*model.Team, error)
+	RemoveMember(ctx context.Context, teamName, userID string) ([]model.ReviewHandover, error)
+	RenameTeam(ctx context.Context, teamName, newTeamName string) (*model.Team, error)
+	DeleteTeam(ctx context.Context, teamName string, force bool) ([]model.ReviewHandover, error)
+	DeactivateUsers(ctx context.Context, teamName string, userIDs []string) ([]model.PRReassignment, error)
 }
 
 type Users interface {
-	SetUserIsActive(ctx context.Context, userID string, isActive bool) (*model.User, error)
+	SetUserIsActive(ctx context.Context, userID string, isActive, reassign bool) (*model.User, []model.ReviewHandover, error)
 	GetUserReview(ctx context.Context, userID string) ([]model.PullRequestShort, error)
+	MoveUser(ctx context.Context, userID, fromTeamName, teamName string, reassign bool) (*model.User, []model.ReviewHandover, error)
+	AddAbsence(ctx context.Context, userID, from, to string) (*model.Absence, error)
 }
 
 type PullRequest interface {
-	CreatePR(ctx context.Context, pullRequestID, pullRequestName, authorID string) (*model.PullRequest, error)
-	MergePR(ctx context.Context, pullRequestID string) (*model.PullRequest, error)
+	CreatePR(ctx context.Context, input model.CreatePRInput) (*model.PullRequest, error)
+	MergePR(ctx context.Context, pullRequestID string, force bool) (*model.PullRequest, error)
+	MarkReady(ctx context.Context, pullRequestID string) (*model.PullRequest, error)
+	ClosePR(ctx context.Context, pullRequestID string) (*model.PullRequest, error)
+	ReopenPR(ctx context.Context, pullRequestID string) (*model.PullRequest, error)
+	GetPR(ctx context.Context, pullRequestID string) (*model.PullRequest, error)
+	GetPRHistory(ctx context.Context, pullRequestID string) (*model.PRHistory, error)
+	ListPRs(ctx context.Context, query model.PRListQuery) (*model.PRListPage, error)
 	ReassignPR(ctx context.Context, pullRequestID, oldReviewerID string) (*model.PullRequest, string, error)
+	SubmitReview(ctx context.Context, pullRequestID, reviewerID, state string) (*model.PullRequest, error)
 }
 
 type Statistics interface {
@@ -28,18 +45,44 @@ type Statistics interface {
 	GetTeamStatistics(ctx context.Context, teamName string) (*model.TeamStatistics, error)
 }
 
+type Auth interface {
+	Authenticate(ctx context.Context, secret string) (*model.Identity, error)
+	IssueToken(ctx context.Context, token model.APIToken) (*model.IssuedToken, error)
+	RevokeToken(ctx context.Context, tokenID string) (*model.APIToken, error)
+}
+
+type Audit interface {
+	ListAuditEvents(ctx context.Context, query model.AuditListQuery) (*model.AuditListPage, error)
+}
+
+type Webhook interface {
+	Subscribe(ctx context.Context, subscription model.WebhookSubscription) (*model.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error)
+	Unsubscribe(ctx context.Context, subscriptionID string) error
+	ListDeliveries(ctx context.Context, subscriptionID, status, limit string) ([]model.WebhookDelivery, error)
+	ReplayDelivery(ctx context.Context, deliveryID int64) (*model.WebhookDelivery, error)
+}
+
 type Service struct {
 	Team
 	Users
 	PullRequest
 	Statistics
+	Auth
+	Audit
+	Webhook
 }
 
-func NewService(r *repository.Repository) *Service {
+// NewService creates the services. Events of committed changes are published by the outbox dispatcher
+// and sent by the webhook dispatcher, both are run separately.
+func NewService(r *repository.Repository, adminToken string, outbox *OutboxDispatcher, webhooks *WebhookDispatcher) *Service {
 	return &Service{
-		Team:        NewTeamService(r),
-		Users:       NewUsersService(r),
-		PullRequest: NewPullRequestService(r),
+		Team:        NewTeamService(r, outbox),
+		Users:       NewUsersService(r, outbox),
+		PullRequest: NewPullRequestService(r, outbox),
 		Statistics:  NewStatisticsService(r),
+		Auth:        NewAuthService(r, adminToken),
+		Audit:       NewAuditService(r),
+		Webhook:     NewWebhookService(r, webhooks),
 	}
 }
@@ -9,10 +9,11 @@ import (
 
 type TeamService struct {
 	repository *repository.Repository
+	events     *OutboxDispatcher
 }
 
-func NewTeamService(r *repository.Repository) *TeamService {
-	return &TeamService{repository: r}
+func NewTeamService(r *repository.Repository, events *OutboxDispatcher) *TeamService {
+	return &TeamService{repository: r, events: events}
 }
 
 func (s *TeamService) AddTeam(ctx context.Context, team model.Team) (*model.Team, error) {
@@ -23,17 +24,250 @@ func (s *TeamService) AddTeam(ctx context.Context, team model.Team) (*model.Team
 		return nil, model.NewEmptyFieldError("members")
 	}
 
-	for _, member := range team.Members {
-		if member.UserID == "" {
-			return nil, model.NewEmptyFieldError("user_id")
-		}
+	if team.AssignmentStrategy == "" {
+		team.AssignmentStrategy = model.StrategyLeastLoaded
+	}
+	if !IsValidStrategy(team.AssignmentStrategy) {
+		return nil, model.NewInvalidFieldError("assignment_strategy")
+	}
+	if team.ReviewersRequired == 0 {
+		team.ReviewersRequired = model.DefaultReviewersRequired
+	}
+	if team.ReviewersRequired < 0 {
+		return nil, model.NewInvalidFieldError("reviewers_required")
+	}
+	if team.ApprovalsRequired < 0 {
+		return nil, model.NewInvalidFieldError("approvals_required")
+	}
+	if err := validateFallbackTeams(team.TeamName, team.FallbackTeams); err != nil {
+		return nil, err
+	}
+	if team.ParentTeamName == team.TeamName {
+		return nil, model.NewInvalidFieldError("parent_team_name")
+	}
+
+	if err := validateMembers(team.Members); err != nil {
+		return nil, err
+	}
+	// Roles of the members of a new team are granted by nobody else, so only admins create teams.
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
 	}
 	return s.repository.AddTeam(ctx, team)
 }
 
-func (s *TeamService) GetTeam(ctx context.Context, teamName string) (*model.Team, error) {
+func (s *TeamService) AddMembers(ctx context.Context, teamName string, members []model.TeamMember) (*model.Team, error) {
 	if teamName == "" {
 		return nil, model.NewEmptyFieldError("team_name")
 	}
-	return s.repository.GetTeam(ctx, teamName)
+	if len(members) == 0 {
+		return nil, model.NewEmptyFieldError("members")
+	}
+	if err := validateMembers(members); err != nil {
+		return nil, err
+	}
+	if err := requireTeamLead(ctx, s.repository, teamName); err != nil {
+		return nil, err
+	}
+	// A lead would otherwise grant themselves the admin role.
+	for _, member := range members {
+		if member.Role == model.RoleLead || member.Role == model.RoleAdmin {
+			if err := requireAdmin(ctx); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	team, err := s.repository.AddMembers(ctx, teamName, members)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Notify()
+	return team, nil
+}
+
+func (s *TeamService) RemoveMember(ctx context.Context, teamName, userID string) ([]model.ReviewHandover, error) {
+	switch {
+	case teamName == "":
+		return nil, model.NewEmptyFieldError("team_name")
+	case userID == "":
+		return nil, model.NewEmptyFieldError("user_id")
+	}
+	if err := requireTeamLead(ctx, s.repository, teamName); err != nil {
+		return nil, err
+	}
+
+	handovers, err := s.repository.RemoveMember(ctx, teamName, userID, pickReviewers)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Notify()
+	return handovers, nil
+}
+
+func (s *TeamService) RenameTeam(ctx context.Context, teamName, newTeamName string) (*model.Team, error) {
+	switch {
+	case teamName == "":
+		return nil, model.NewEmptyFieldError("team_name")
+	case newTeamName == "":
+		return nil, model.NewEmptyFieldError("new_team_name")
+	case newTeamName == teamName:
+		return nil, model.NewInvalidFieldError("new_team_name")
+	}
+	if err := requireTeamLead(ctx, s.repository, teamName); err != nil {
+		return nil, err
+	}
+	return s.repository.RenameTeam(ctx, teamName, newTeamName)
+}
+
+func (s *TeamService) DeleteTeam(ctx context.Context, teamName string, force bool) ([]model.ReviewHandover, error) {
+	if teamName == "" {
+		return nil, model.NewEmptyFieldError("team_name")
+	}
+	if err := requireTeamLead(ctx, s.repository, teamName); err != nil {
+		return nil, err
+	}
+
+	handovers, err := s.repository.DeleteTeam(ctx, teamName, force, pickReviewers)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Notify()
+	return handovers, nil
+}
+
+func (s *TeamService) DeactivateUsers(ctx context.Context, teamName string, userIDs []string) ([]model.PRReassignment, error) {
+	switch {
+	case teamName == "":
+		return nil, model.NewEmptyFieldError("team_name")
+	case len(userIDs) == 0:
+		return nil, model.NewEmptyFieldError("user_ids")
+	}
+
+	seen := make(map[string]struct{}, len(userIDs))
+	uniqueIDs := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if userID == "" {
+			return nil, model.NewEmptyFieldError("user_ids")
+		}
+		if _, ok := seen[userID]; !ok {
+			seen[userID] = struct{}{}
+			uniqueIDs = append(uniqueIDs, userID)
+		}
+	}
+	if err := requireTeamLead(ctx, s.repository, teamName); err != nil {
+		return nil, err
+	}
+
+	reassignments, err := s.repository.DeactivateUsers(ctx, teamName, uniqueIDs, pickReviewers)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Notify()
+	return reassignments, nil
+}
+
+func (s *TeamService) GetTeam(ctx context.Context, teamName string, withSubTeams bool) (*model.Team, error) {
+	if teamName == "" {
+		return nil, model.NewEmptyFieldError("team_name")
+	}
+	return s.repository.GetTeam(ctx, teamName, withSubTeams)
+}
+
+func (s *TeamService) UpdateTeamSettings(ctx context.Context, settings model.TeamSettings) (*model.TeamSettings, error) {
+	switch {
+	case settings.TeamName == "":
+		return nil, model.NewEmptyFieldError("team_name")
+	case settings.AssignmentStrategy != "" && !IsValidStrategy(settings.AssignmentStrategy):
+		return nil, model.NewInvalidFieldError("assignment_strategy")
+	case settings.ReviewersRequired < 0:
+		return nil, model.NewInvalidFieldError("reviewers_required")
+	case settings.ApprovalsRequired != nil && *settings.ApprovalsRequired < 0:
+		return nil, model.NewInvalidFieldError("approvals_required")
+	case settings.ParentTeamName != nil && *settings.ParentTeamName == settings.TeamName:
+		return nil, model.NewInvalidFieldError("parent_team_name")
+	}
+	if err := validateFallbackTeams(settings.TeamName, settings.FallbackTeams); err != nil {
+		return nil, err
+	}
+
+	// Moving the team under another parent is up to the leads of both teams.
+	teamNames := []string{settings.TeamName}
+	if settings.ParentTeamName != nil && *settings.ParentTeamName != "" {
+		teamNames = append(teamNames, *settings.ParentTeamName)
+	}
+	if err := requireTeamLead(ctx, s.repository, teamNames...); err != nil {
+		return nil, err
+	}
+	return s.repository.UpdateTeamSettings(ctx, settings)
+}
+
+func (s *TeamService) SetCodeOwners(ctx context.Context, owners model.CodeOwners) (*model.CodeOwners, error) {
+	if owners.TeamName == "" {
+		return nil, model.NewEmptyFieldError("team_name")
+	}
+	for _, rule := range owners.Rules {
+		if err := validateCodeOwnerRule(rule); err != nil {
+			return nil, err
+		}
+	}
+	if err := requireTeamLead(ctx, s.repository, owners.TeamName); err != nil {
+		return nil, err
+	}
+	return s.repository.SetCodeOwners(ctx, owners)
+}
+
+func (s *TeamService) GetCodeOwners(ctx context.Context, teamName string) (*model.CodeOwners, error) {
+	if teamName == "" {
+		return nil, model.NewEmptyFieldError("team_name")
+	}
+	return s.repository.GetCodeOwners(ctx, teamName)
+}
+
+// validateMembers checks the members and sets the default review weight.
+func validateMembers(members []model.TeamMember) error {
+	for i, member := range members {
+		if member.UserID == "" {
+			return model.NewEmptyFieldError("user_id")
+		}
+		if member.ReviewWeight < 0 {
+			return model.NewInvalidFieldError("review_weight")
+		}
+		if member.ReviewWeight == 0 {
+			members[i].ReviewWeight = 1
+		}
+		if member.MaxOpenReviews != nil && *member.MaxOpenReviews < 0 {
+			return model.NewInvalidFieldError("max_open_reviews")
+		}
+		if member.Role == "" {
+			members[i].Role = model.RoleMember
+		}
+		if !IsValidRole(members[i].Role) {
+			return model.NewInvalidFieldError("role")
+		}
+	}
+	return nil
+}
+
+func IsValidRole(role string) bool {
+	switch role {
+	case model.RoleMember, model.RoleLead, model.RoleAdmin:
+		return true
+	}
+	return false
+}
+
+func validateFallbackTeams(teamName string, fallbackTeams []string) error {
+	seen := make(map[string]bool, len(fallbackTeams))
+	for _, fallbackTeam := range fallbackTeams {
+		if fallbackTeam == "" {
+			return model.NewEmptyFieldError("fallback_teams")
+		}
+		if fallbackTeam == teamName || seen[fallbackTeam] {
+			return model.NewInvalidFieldError("fallback_teams")
+		}
+		seen[fallbackTeam] = true
+	}
+	return nil
 }
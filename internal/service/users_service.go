@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/karambo3a/avito_test_task/internal/model"
 	"github.com/karambo3a/avito_test_task/internal/repository"
@@ -9,17 +12,27 @@ import (
 
 type UsersService struct {
 	repository *repository.Repository
+	events     *OutboxDispatcher
 }
 
-func NewUsersService(r *repository.Repository) *UsersService {
-	return &UsersService{repository: r}
+func NewUsersService(r *repository.Repository, events *OutboxDispatcher) *UsersService {
+	return &UsersService{repository: r, events: events}
 }
 
-func (s *UsersService) SetUserIsActive(ctx context.Context, userID string, isActive bool) (*model.User, error) {
+func (s *UsersService) SetUserIsActive(ctx context.Context, userID string, isActive, reassign bool) (*model.User, []model.ReviewHandover, error) {
 	if userID == "" {
-		return nil, model.NewEmptyFieldError("user_id")
+		return nil, nil, model.NewEmptyFieldError("user_id")
+	}
+	if err := requireUserLead(ctx, s.repository, userID); err != nil {
+		return nil, nil, err
+	}
+
+	user, handovers, err := s.repository.SetUserIsActive(ctx, userID, isActive, reassign, pickReviewers)
+	if err != nil {
+		return nil, nil, err
 	}
-	return s.repository.SetUserIsActive(ctx, userID, isActive)
+	s.events.Notify()
+	return user, handovers, nil
 }
 
 func (s *UsersService) GetUserReview(ctx context.Context, userID string) ([]model.PullRequestShort, error) {
@@ -28,3 +41,61 @@ func (s *UsersService) GetUserReview(ctx context.Context, userID string) ([]mode
 	}
 	return s.repository.GetUserReview(ctx, userID)
 }
+
+func (s *UsersService) MoveUser(ctx context.Context, userID, fromTeamName, teamName string, reassign bool) (*model.User, []model.ReviewHandover, error) {
+	switch {
+	case userID == "":
+		return nil, nil, model.NewEmptyFieldError("user_id")
+	case teamName == "":
+		return nil, nil, model.NewEmptyFieldError("team_name")
+	}
+
+	// Without from_team_name the user may leave any of their teams, so all of them must be led by the caller.
+	teamNames := []string{teamName, fromTeamName}
+	if fromTeamName == "" {
+		roles, err := s.repository.GetTeamRoles(ctx, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		teamNames = append(teamNames[:1], slices.Collect(maps.Keys(roles))...)
+	}
+	if err := requireTeamLead(ctx, s.repository, teamNames...); err != nil {
+		return nil, nil, err
+	}
+
+	user, handovers, err := s.repository.MoveUser(ctx, userID, fromTeamName, teamName, reassign, pickReviewers)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.events.Notify()
+	return user, handovers, nil
+}
+
+func (s *UsersService) AddAbsence(ctx context.Context, userID, from, to string) (*model.Absence, error) {
+	switch {
+	case userID == "":
+		return nil, model.NewEmptyFieldError("user_id")
+	case from == "":
+		return nil, model.NewEmptyFieldError("from")
+	case to == "":
+		return nil, model.NewEmptyFieldError("to")
+	}
+
+	absentFrom, err := time.Parse(time.RFC3339, from)
+	if err != nil {
+		return nil, model.NewInvalidFieldError("from")
+	}
+	absentTo, err := time.Parse(time.RFC3339, to)
+	if err != nil || !absentTo.After(absentFrom) {
+		return nil, model.NewInvalidFieldError("to")
+	}
+	if err = requireUserLead(ctx, s.repository, userID); err != nil {
+		return nil, err
+	}
+
+	return s.repository.AddAbsence(ctx, model.Absence{
+		UserID: userID,
+		From:   absentFrom.UTC(),
+		To:     absentTo.UTC(),
+	})
+}
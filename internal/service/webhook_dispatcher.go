@@ -0,0 +1,162 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/karambo3a/avito_test_task/internal/model"
+	"github.com/karambo3a/avito_test_task/internal/repository"
+)
+
+const (
+	webhookBatchSize    = 10
+	webhookPollInterval = time.Second
+	webhookTimeout      = 5 * time.Second
+	// webhookLease must exceed webhookTimeout, so that a delivery being sent is not claimed again.
+	webhookLease       = 30 * time.Second
+	webhookMaxAttempts = 6
+	webhookMaxBackoff  = 10 * time.Minute
+
+	DefaultWebhookRetryBase = 10 * time.Second
+)
+
+// WebhookDispatcher sends the queued deliveries in the background.
+type WebhookDispatcher struct {
+	repository *repository.Repository
+	client     *http.Client
+	retryBase  time.Duration
+	wake       chan struct{}
+}
+
+// NewWebhookDispatcher creates the dispatcher. A failed delivery is retried after retryBase,
+// each next retry waits twice as long.
+func NewWebhookDispatcher(r *repository.Repository, retryBase time.Duration) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repository: r,
+		client:     &http.Client{Timeout: webhookTimeout},
+		retryBase:  retryBase,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// Notify wakes the dispatcher up to send the deliveries that are due.
+func (d *WebhookDispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run sends due deliveries until ctx is canceled. Deliveries being sent are finished before it returns.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.dispatchDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *WebhookDispatcher) dispatchDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		dispatches, err := d.repository.ClaimDeliveries(ctx, webhookBatchSize, webhookLease)
+		if err != nil {
+			log.Printf("webhook claim error: %v", err)
+			return
+		}
+
+		// A claimed delivery is sent and recorded even if the dispatcher is being stopped.
+		var wg sync.WaitGroup
+		for _, dispatch := range dispatches {
+			wg.Go(func() {
+				d.deliver(context.WithoutCancel(ctx), dispatch)
+			})
+		}
+		wg.Wait()
+
+		if len(dispatches) < webhookBatchSize {
+			return
+		}
+	}
+}
+
+// deliver makes one attempt to send the delivery and records its outcome.
+func (d *WebhookDispatcher) deliver(ctx context.Context, dispatch model.WebhookDispatch) {
+	attempt := model.WebhookAttempt{AttemptedAt: time.Now()}
+	statusCode, err := d.send(ctx, dispatch)
+	attempt.DurationMS = time.Since(attempt.AttemptedAt).Milliseconds()
+	attempt.StatusCode = statusCode
+
+	status, retryIn := model.DeliveryStatusDelivered, time.Duration(0)
+	if err != nil {
+		attempt.Error = err.Error()
+		status, retryIn = d.retry(dispatch.AttemptCount + 1)
+		log.Printf("webhook delivery %d attempt %d error: %v", dispatch.DeliveryID, dispatch.AttemptCount+1, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	if err = d.repository.RecordAttempt(ctx, dispatch.DeliveryID, attempt, status, retryIn); err != nil {
+		log.Printf("webhook delivery %d record error: %v", dispatch.DeliveryID, err)
+	}
+}
+
+// send posts the signed payload and returns the response status, a non-2xx status is an error.
+func (d *WebhookDispatcher) send(ctx context.Context, dispatch model.WebhookDispatch) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dispatch.URL, bytes.NewReader(dispatch.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(model.WebhookEventHeader, dispatch.EventType)
+	req.Header.Set(model.WebhookDeliveryHeader, strconv.FormatInt(dispatch.DeliveryID, 10))
+	req.Header.Set(model.WebhookSignatureHeader, "sha256="+signWebhookPayload(dispatch.Secret, dispatch.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// retry returns the status of a delivery after its failed attempt and the delay before the next one.
+func (d *WebhookDispatcher) retry(attempts int) (string, time.Duration) {
+	if attempts >= webhookMaxAttempts {
+		return model.DeliveryStatusFailed, 0
+	}
+
+	backoff := d.retryBase << (attempts - 1)
+	if backoff <= 0 || backoff > webhookMaxBackoff {
+		backoff = webhookMaxBackoff
+	}
+	return model.DeliveryStatusPending, backoff
+}
+
+func signWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
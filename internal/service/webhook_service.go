@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/karambo3a/avito_test_task/internal/model"
+	"github.com/karambo3a/avito_test_task/internal/repository"
+)
+
+type WebhookService struct {
+	repository *repository.Repository
+	dispatcher *WebhookDispatcher
+}
+
+func NewWebhookService(r *repository.Repository, dispatcher *WebhookDispatcher) *WebhookService {
+	return &WebhookService{repository: r, dispatcher: dispatcher}
+}
+
+const (
+	defaultDeliveryListLimit = 50
+	maxDeliveryListLimit     = 500
+)
+
+// Subscribe creates the subscription. A secret is generated when none is given.
+func (s *WebhookService) Subscribe(ctx context.Context, subscription model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	switch {
+	case subscription.URL == "":
+		return nil, model.NewEmptyFieldError("url")
+	case len(subscription.EventTypes) == 0:
+		return nil, model.NewEmptyFieldError("event_types")
+	}
+
+	target, err := url.Parse(subscription.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, model.NewInvalidFieldError("url")
+	}
+
+	eventTypes := make([]string, 0, len(subscription.EventTypes))
+	for _, eventType := range subscription.EventTypes {
+		if !slices.Contains(model.WebhookEventTypes, eventType) {
+			return nil, model.NewInvalidFieldError("event_types")
+		}
+		if !slices.Contains(eventTypes, eventType) {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	subscription.EventTypes = eventTypes
+
+	if subscription.SubscriptionID, err = randomHex(8); err != nil {
+		return nil, err
+	}
+	if subscription.Secret == "" {
+		if subscription.Secret, err = randomHex(32); err != nil {
+			return nil, err
+		}
+	}
+	return s.repository.CreateSubscription(ctx, subscription)
+}
+
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	return s.repository.ListSubscriptions(ctx)
+}
+
+func (s *WebhookService) Unsubscribe(ctx context.Context, subscriptionID string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	if subscriptionID == "" {
+		return model.NewEmptyFieldError("subscription_id")
+	}
+	return s.repository.DeleteSubscription(ctx, subscriptionID)
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, subscriptionID, status, limit string) ([]model.WebhookDelivery, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	filter := model.WebhookDeliveryFilter{
+		SubscriptionID: subscriptionID,
+		Status:         status,
+		Limit:          defaultDeliveryListLimit,
+	}
+
+	switch status {
+	case "", model.DeliveryStatusPending, model.DeliveryStatusDelivered, model.DeliveryStatusFailed:
+	default:
+		return nil, model.NewInvalidFieldError("status")
+	}
+
+	if limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 || value > maxDeliveryListLimit {
+			return nil, model.NewInvalidFieldError("limit")
+		}
+		filter.Limit = value
+	}
+	return s.repository.ListDeliveries(ctx, filter)
+}
+
+// ReplayDelivery sends the delivery again right away, e.g. after the subscriber fixed its endpoint.
+func (s *WebhookService) ReplayDelivery(ctx context.Context, deliveryID int64) (*model.WebhookDelivery, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if deliveryID <= 0 {
+		return nil, model.NewEmptyFieldError("delivery_id")
+	}
+
+	delivery, err := s.repository.ReplayDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	s.dispatcher.Notify()
+	return delivery, nil
+}
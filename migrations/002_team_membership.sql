@@ -0,0 +1,47 @@
+-- Moves existing databases from users.team_name to the team_member table and adds
+-- the team and pr columns that came later, so both tables match init.sql.
+-- Fresh databases get the new schema from init.sql and don't need this script.
+BEGIN;
+
+ALTER TABLE team ADD COLUMN IF NOT EXISTS require_lead_review BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE team ADD COLUMN IF NOT EXISTS parent_team_name VARCHAR(255)
+    REFERENCES team(team_name) ON UPDATE CASCADE ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS team_parent_idx ON team(parent_team_name);
+
+CREATE TABLE IF NOT EXISTS team_member (
+    team_name VARCHAR(255),
+    user_id VARCHAR(255),
+
+    FOREIGN KEY (team_name) REFERENCES team(team_name) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(user_id),
+
+    PRIMARY KEY (team_name, user_id)
+);
+
+ALTER TABLE team_member ADD COLUMN IF NOT EXISTS role VARCHAR(16) NOT NULL DEFAULT 'member'
+    CHECK (role IN ('member', 'lead', 'admin'));
+
+CREATE INDEX IF NOT EXISTS team_member_user_idx ON team_member(user_id);
+
+INSERT INTO team_member (team_name, user_id)
+SELECT team_name, user_id
+FROM users
+WHERE team_name IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE pr ADD COLUMN IF NOT EXISTS team_name VARCHAR(255)
+    REFERENCES team(team_name) ON UPDATE CASCADE ON DELETE SET NULL;
+ALTER TABLE pr ADD COLUMN IF NOT EXISTS force_merged_by VARCHAR(255) DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS pr_team_name_idx ON pr(team_name);
+
+UPDATE pr
+SET team_name = u.team_name
+FROM users AS u
+WHERE u.user_id = pr.author_id AND pr.team_name IS NULL;
+
+DROP INDEX IF EXISTS is_active_team_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS team_name;
+
+COMMIT;
@@ -1,36 +1,117 @@
 CREATE TABLE IF NOT EXISTS team (
-    team_name VARCHAR(255) PRIMARY KEY
+    team_name VARCHAR(255) PRIMARY KEY,
+    assignment_strategy VARCHAR(32) NOT NULL DEFAULT 'least_loaded',
+    reviewers_required INT NOT NULL DEFAULT 2,
+    approvals_required INT NOT NULL DEFAULT 0,
+    require_lead_review BOOLEAN NOT NULL DEFAULT false,
+    parent_team_name VARCHAR(255),
+
+    FOREIGN KEY (parent_team_name) REFERENCES team(team_name) ON UPDATE CASCADE ON DELETE SET NULL
+);
+
+CREATE INDEX team_parent_idx ON team(parent_team_name);
+
+CREATE TABLE IF NOT EXISTS team_fallback (
+    team_name VARCHAR(255),
+    fallback_team_name VARCHAR(255),
+    priority INT NOT NULL DEFAULT 0,
+
+    FOREIGN KEY (team_name) REFERENCES team(team_name) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY (fallback_team_name) REFERENCES team(team_name) ON UPDATE CASCADE ON DELETE CASCADE,
+
+    PRIMARY KEY (team_name, fallback_team_name)
 );
 
 CREATE TABLE IF NOT EXISTS users (
     user_id VARCHAR(255) PRIMARY KEY,
     username VARCHAR(255) NOT NULL,
-    team_name VARCHAR(255) NOT NULL,
     is_active BOOLEAN DEFAULT TRUE,
-
-    FOREIGN KEY (team_name) REFERENCES team(team_name)
+    review_weight INT NOT NULL DEFAULT 1,
+    max_open_reviews INT DEFAULT NULL CHECK (max_open_reviews >= 0)
 );
 
-CREATE INDEX is_active_team_idx ON users(team_name, is_active);
 CREATE INDEX is_active_user_idx ON users(user_id, is_active);
 
+CREATE TABLE IF NOT EXISTS team_member (
+    team_name VARCHAR(255),
+    user_id VARCHAR(255),
+    role VARCHAR(16) NOT NULL DEFAULT 'member' CHECK (role IN ('member', 'lead', 'admin')),
+
+    FOREIGN KEY (team_name) REFERENCES team(team_name) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(user_id),
+
+    PRIMARY KEY (team_name, user_id)
+);
+
+CREATE INDEX team_member_user_idx ON team_member(user_id);
+
+CREATE TABLE IF NOT EXISTS user_absence (
+    user_id VARCHAR(255),
+    absent_from TIMESTAMP NOT NULL,
+    absent_to TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (user_id) REFERENCES users(user_id),
+
+    PRIMARY KEY (user_id, absent_from, absent_to),
+    CHECK (absent_from < absent_to)
+);
+
+CREATE INDEX user_absence_to_idx ON user_absence(absent_to);
+
+CREATE TABLE IF NOT EXISTS code_owner_rule (
+    team_name VARCHAR(255) NOT NULL,
+    position INT NOT NULL,
+    pattern VARCHAR(255) NOT NULL,
+    owner_user_id VARCHAR(255),
+    owner_team_name VARCHAR(255),
+
+    FOREIGN KEY (team_name) REFERENCES team(team_name) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY (owner_user_id) REFERENCES users(user_id),
+    FOREIGN KEY (owner_team_name) REFERENCES team(team_name) ON UPDATE CASCADE ON DELETE CASCADE,
+
+    CHECK ((owner_user_id IS NULL) <> (owner_team_name IS NULL))
+);
+
+CREATE INDEX code_owner_rule_team_idx ON code_owner_rule(team_name, position);
+
 CREATE TABLE IF NOT EXISTS pr (
     pr_id VARCHAR(255) PRIMARY KEY,
     pr_name VARCHAR(255) NOT NULL,
     author_id VARCHAR(255) NOT NULL,
-    status VARCHAR(6) DEFAULT 'OPEN',
+    team_name VARCHAR(255),
+    status VARCHAR(6) DEFAULT 'OPEN'
+        CHECK (status IN ('DRAFT', 'OPEN', 'MERGED', 'CLOSED')),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     merged_at TIMESTAMP DEFAULT NULL,
+    force_merged BOOLEAN NOT NULL DEFAULT FALSE,
+    force_merged_by VARCHAR(255) DEFAULT NULL,
 
-    FOREIGN KEY (author_id) REFERENCES users(user_id)
+    FOREIGN KEY (author_id) REFERENCES users(user_id),
+    FOREIGN KEY (team_name) REFERENCES team(team_name) ON UPDATE CASCADE ON DELETE SET NULL
 );
 
 CREATE INDEX pr_author_id_idx ON pr(author_id);
+CREATE INDEX pr_team_name_idx ON pr(team_name);
 CREATE INDEX pr_status_idx ON pr(status);
+CREATE INDEX pr_created_at_idx ON pr(created_at DESC, pr_id DESC);
+CREATE INDEX pr_merged_at_idx ON pr(merged_at);
+
+CREATE TABLE IF NOT EXISTS pr_file (
+    pr_id VARCHAR(255),
+    file_path VARCHAR(1024),
+
+    FOREIGN KEY (pr_id) REFERENCES pr(pr_id),
+
+    PRIMARY KEY (pr_id, file_path)
+);
 
 CREATE TABLE IF NOT EXISTS reviewer_x_pr (
     user_id VARCHAR(255),
     pr_id VARCHAR(255),
+    assigned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    is_fallback BOOLEAN NOT NULL DEFAULT FALSE,
+    state VARCHAR(17) NOT NULL DEFAULT 'PENDING',
+    reviewed_at TIMESTAMP DEFAULT NULL,
 
     FOREIGN KEY (user_id) REFERENCES users(user_id),
     FOREIGN KEY (pr_id) REFERENCES pr(pr_id),
@@ -39,3 +120,135 @@ CREATE TABLE IF NOT EXISTS reviewer_x_pr (
 );
 
 CREATE INDEX reviewer_x_pr_pr_id_idx ON reviewer_x_pr(pr_id);
+
+CREATE TABLE IF NOT EXISTS reviewer_assignment (
+    assignment_id BIGSERIAL PRIMARY KEY,
+    pr_id VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    assigned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reason VARCHAR(16) NOT NULL
+        CHECK (reason IN ('initial', 'reassign', 'deactivation', 'membership')),
+    unassigned_at TIMESTAMP DEFAULT NULL,
+    unassign_reason VARCHAR(16) DEFAULT NULL
+        CHECK (unassign_reason IN ('reassign', 'deactivation', 'membership')),
+
+    FOREIGN KEY (pr_id) REFERENCES pr(pr_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id)
+);
+
+CREATE INDEX reviewer_assignment_pr_id_idx ON reviewer_assignment(pr_id, assignment_id);
+CREATE UNIQUE INDEX reviewer_assignment_current_idx ON reviewer_assignment(pr_id, user_id) WHERE unassigned_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS pr_status_change (
+    change_id BIGSERIAL PRIMARY KEY,
+    pr_id VARCHAR(255) NOT NULL,
+    from_status VARCHAR(6) DEFAULT NULL,
+    to_status VARCHAR(6) NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (pr_id) REFERENCES pr(pr_id)
+);
+
+CREATE INDEX pr_status_change_pr_id_idx ON pr_status_change(pr_id, change_id);
+
+CREATE TABLE IF NOT EXISTS api_token (
+    token_id VARCHAR(32) PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    user_id VARCHAR(255),
+    service_account VARCHAR(255),
+    is_admin BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP DEFAULT NULL,
+
+    FOREIGN KEY (user_id) REFERENCES users(user_id),
+
+    CHECK ((user_id IS NULL) <> (service_account IS NULL))
+);
+
+CREATE INDEX api_token_user_id_idx ON api_token(user_id);
+
+CREATE TABLE IF NOT EXISTS audit_event (
+    event_id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    entity_type VARCHAR(32) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    before JSONB DEFAULT NULL,
+    after JSONB DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX audit_event_entity_idx ON audit_event(entity_type, entity_id, event_id DESC);
+CREATE INDEX audit_event_actor_idx ON audit_event(actor, event_id DESC);
+CREATE INDEX audit_event_created_at_idx ON audit_event(created_at);
+
+CREATE OR REPLACE FUNCTION audit_event_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_event is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_event_append_only
+BEFORE UPDATE OR DELETE ON audit_event
+FOR EACH ROW EXECUTE FUNCTION audit_event_append_only();
+
+CREATE TABLE IF NOT EXISTS webhook_subscription (
+    subscription_id VARCHAR(32) PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS webhook_subscription_event (
+    subscription_id VARCHAR(32) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+
+    PRIMARY KEY (subscription_id, event_type),
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscription(subscription_id) ON DELETE CASCADE
+);
+
+CREATE INDEX webhook_subscription_event_type_idx ON webhook_subscription_event(event_type);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+    delivery_id BIGSERIAL PRIMARY KEY,
+    subscription_id VARCHAR(32) NOT NULL,
+    event_id VARCHAR(32) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(9) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempt_count INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP DEFAULT NULL,
+
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscription(subscription_id) ON DELETE CASCADE,
+
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX webhook_delivery_due_idx ON webhook_delivery(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_delivery_subscription_idx ON webhook_delivery(subscription_id, delivery_id DESC);
+
+CREATE TABLE IF NOT EXISTS webhook_attempt (
+    attempt_id BIGSERIAL PRIMARY KEY,
+    delivery_id BIGINT NOT NULL,
+    attempted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    status_code INTEGER DEFAULT NULL,
+    error TEXT DEFAULT NULL,
+    duration_ms BIGINT NOT NULL,
+
+    FOREIGN KEY (delivery_id) REFERENCES webhook_delivery(delivery_id) ON DELETE CASCADE
+);
+
+CREATE INDEX webhook_attempt_delivery_idx ON webhook_attempt(delivery_id, attempt_id);
+
+CREATE TABLE IF NOT EXISTS outbox_event (
+    outbox_id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(64) NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE INDEX outbox_event_unpublished_idx ON outbox_event(outbox_id) WHERE published_at IS NULL;
@@ -0,0 +1,187 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/karambo3a/avito_test_task/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("audit-team-%d", timestamp)
+	authorID := fmt.Sprintf("audit-author-%d", timestamp)
+	prID := fmt.Sprintf("audit-pr-%d", timestamp)
+
+	// A required approval nobody gives makes the merge below a force merge.
+	_, statusCode, err := client.AddTeam(&model.Team{
+		TeamName:          teamName,
+		ReviewersRequired: 1,
+		ApprovalsRequired: 1,
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "Audit Author", IsActive: true},
+			{UserID: fmt.Sprintf("audit-first-%d", timestamp), Username: "Audit First", IsActive: true},
+			{UserID: fmt.Sprintf("audit-second-%d", timestamp), Username: "Audit Second", IsActive: true},
+		},
+	})
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	resp, statusCode, err := client.IssueToken(&model.APIToken{UserID: authorID})
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Issuing token should succeed")
+	authorClient := client.WithToken(resp.(model.IssuedToken).Secret)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	listEvents := func(t *testing.T, params url.Values) model.AuditListPage {
+		resp, statusCode, err := client.ListAuditEvents(params)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Listing audit events should succeed")
+		return resp.(model.AuditListPage)
+	}
+
+	t.Run("Team creation is recorded", func(t *testing.T) {
+		page := listEvents(t, url.Values{"entity_type": {model.AuditEntityTeam}, "entity_id": {teamName}})
+		require.Len(t, page.Events, 1, "One event should be recorded")
+		event := page.Events[0]
+		assert.Equal(t, model.AuditTeamCreate, event.Action, "Action should match")
+		assert.Equal(t, "service:bootstrap", event.Actor, "Actor should be the admin token")
+		assert.Empty(t, event.Before, "Created team should have no previous state")
+
+		var after model.Team
+		require.NoError(t, json.Unmarshal(event.After, &after), "State after should be a team")
+		assert.Equal(t, teamName, after.TeamName, "Team name should match")
+		assert.Len(t, after.Members, 3, "All members should be recorded")
+	})
+
+	t.Run("Failed mutations are not recorded", func(t *testing.T) {
+		_, statusCode, err := client.AddTeam(&model.Team{
+			TeamName: teamName,
+			Members:  []model.TeamMember{{UserID: authorID, Username: "Audit Author", IsActive: true}},
+		})
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusBadRequest, statusCode, "Duplicate team should be rejected")
+
+		count, err := dbVerifier.CountAuditEvents(ctx, model.AuditEntityTeam, teamName)
+		require.NoError(t, err, "Counting audit events should not fail")
+		assert.Equal(t, 1, count, "Rejected change should not be recorded")
+	})
+
+	_, statusCode, err = client.CreatePR(prID, "Audit PR", authorID)
+	require.NoError(t, err, "Creating PR should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+	dbPR, err := dbVerifier.GetPullRequest(ctx, prID)
+	require.NoError(t, err, "Getting PR from database should not fail")
+	require.Len(t, dbPR.AssignedReviewers, 1, "One reviewer should be assigned")
+	oldReviewerID := dbPR.AssignedReviewers[0].UserID
+
+	resp, statusCode, err = client.IssueToken(&model.APIToken{UserID: oldReviewerID})
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Issuing token should succeed")
+	reviewerClient := client.WithToken(resp.(model.IssuedToken).Secret)
+
+	_, statusCode, err = reviewerClient.ReassignPR(prID, oldReviewerID)
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "Reassignment should succeed")
+
+	_, statusCode, err = authorClient.ForceMergePR(prID)
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusForbidden, statusCode, "Only admins should force a merge")
+
+	_, statusCode, err = client.ForceMergePR(prID)
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "Admin should force the merge")
+
+	t.Run("PR changes are recorded with states", func(t *testing.T) {
+		page := listEvents(t, url.Values{"entity_type": {model.AuditEntityPullRequest}, "entity_id": {prID}})
+		require.Len(t, page.Events, 3, "Three events should be recorded")
+		assert.Equal(t, model.AuditPRMerge, page.Events[0].Action, "Newest event should be first")
+		assert.Equal(t, model.AuditPRReassign, page.Events[1].Action, "Reassignment should be recorded")
+		assert.Equal(t, model.AuditPRCreate, page.Events[2].Action, "Creation should be recorded")
+
+		var before, after struct {
+			Reviewers []string `json:"reviewers"`
+		}
+		require.NoError(t, json.Unmarshal(page.Events[1].Before, &before), "State before should be reviewers")
+		require.NoError(t, json.Unmarshal(page.Events[1].After, &after), "State after should be reviewers")
+		assert.Equal(t, []string{oldReviewerID}, before.Reviewers, "Old reviewer should be recorded")
+		require.Len(t, after.Reviewers, 1, "One reviewer should remain")
+		assert.NotEqual(t, oldReviewerID, after.Reviewers[0], "New reviewer should be recorded")
+
+		var merged model.PullRequest
+		require.NoError(t, json.Unmarshal(page.Events[0].After, &merged), "State after should be a PR")
+		assert.Equal(t, model.PRStatusMerged, merged.Status, "Merged status should be recorded")
+		assert.True(t, merged.ForceMerged, "Force merge should be recorded")
+		assert.Equal(t, "service:bootstrap", merged.ForceMergedBy, "Admin forcing the merge should be recorded")
+		assert.Equal(t, "service:bootstrap", page.Events[0].Actor, "Merge should be recorded for the admin")
+	})
+
+	t.Run("Events are filtered by actor and action", func(t *testing.T) {
+		page := listEvents(t, url.Values{"actor": {oldReviewerID}, "entity_id": {prID}})
+		require.Len(t, page.Events, 1, "Only the reviewer's event should be listed")
+		assert.Equal(t, model.AuditPRReassign, page.Events[0].Action, "Action should match")
+
+		page = listEvents(t, url.Values{"action": {model.AuditPRCreate}, "entity_id": {prID}})
+		require.Len(t, page.Events, 1, "Only creation should be listed")
+		assert.Equal(t, "service:bootstrap", page.Events[0].Actor, "Actor should match")
+
+		page = listEvents(t, url.Values{"entity_id": {prID}, "from": {time.Now().Add(time.Hour).UTC().Format(time.RFC3339)}})
+		assert.Empty(t, page.Events, "No events should be in the future")
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		params := url.Values{"entity_id": {prID}, "limit": {"2"}}
+		first := listEvents(t, params)
+		require.Len(t, first.Events, 2, "First page should be full")
+		require.NotEmpty(t, first.NextCursor, "First page should have a next cursor")
+
+		params.Set("cursor", first.NextCursor)
+		second := listEvents(t, params)
+		require.Len(t, second.Events, 1, "Second page should have the rest")
+		assert.Empty(t, second.NextCursor, "Last page should have no next cursor")
+		assert.Less(t, second.Events[0].EventID, first.Events[1].EventID, "Pages should not overlap")
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		for _, params := range []url.Values{
+			{"entity_type": {"unknown"}},
+			{"limit": {"0"}},
+			{"from": {"yesterday"}},
+			{"cursor": {"!"}},
+		} {
+			_, statusCode, err := client.ListAuditEvents(params)
+			require.NoError(t, err, "API call should not fail")
+			assert.Equal(t, http.StatusBadRequest, statusCode, "%v should be rejected", params)
+		}
+	})
+
+	t.Run("Only admins read the audit log", func(t *testing.T) {
+		_, statusCode, err := authorClient.ListAuditEvents(url.Values{"entity_id": {prID}})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Non-admin should not read the audit log")
+	})
+
+	t.Run("Events can't be deleted", func(t *testing.T) {
+		err := dbVerifier.DeleteAuditEvents(ctx, model.AuditEntityPullRequest, prID)
+		assert.Error(t, err, "Deleting audit events should fail")
+
+		count, err := dbVerifier.CountAuditEvents(ctx, model.AuditEntityPullRequest, prID)
+		require.NoError(t, err, "Counting audit events should not fail")
+		assert.Equal(t, 3, count, "Events should be kept")
+	})
+}
@@ -0,0 +1,405 @@
+package integration
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/karambo3a/avito_test_task/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthentication(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("auth-team-%d", timestamp)
+	userID := fmt.Sprintf("auth-user-%d", timestamp)
+	teamAdminID := fmt.Sprintf("auth-team-admin-%d", timestamp)
+
+	_, statusCode, err := client.AddTeam(&model.Team{
+		TeamName: teamName,
+		Members: []model.TeamMember{
+			{UserID: userID, Username: "Auth User", IsActive: true},
+			{UserID: teamAdminID, Username: "Auth Team Admin", IsActive: true, Role: model.RoleAdmin},
+		},
+	})
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("Requests without a valid token are rejected", func(t *testing.T) {
+		for _, token := range []string{"", "invalid-token"} {
+			resp, statusCode, err := client.WithToken(token).GetTeam(teamName)
+			require.NoError(t, err, "API call should not fail")
+			require.Equal(t, http.StatusUnauthorized, statusCode, "Request should be unauthorized")
+			errorMap, ok := resp.(map[string]interface{})["error"].(map[string]interface{})
+			require.True(t, ok, "Error response should have 'error' field")
+			assert.Equal(t, model.CodeUnauthorized, errorMap["code"], "Error code should match expected")
+		}
+	})
+
+	t.Run("Invalid token owners", func(t *testing.T) {
+		testCases := []struct {
+			name           string
+			token          *model.APIToken
+			expectedStatus int
+		}{
+			{"No owner", &model.APIToken{}, http.StatusBadRequest},
+			{"User and service account", &model.APIToken{UserID: userID, ServiceAccount: "ci"}, http.StatusBadRequest},
+			{"Unknown user", &model.APIToken{UserID: fmt.Sprintf("auth-missing-%d", timestamp)}, http.StatusNotFound},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, statusCode, err := client.IssueToken(tc.token)
+				require.NoError(t, err, "API call should not fail")
+				assert.Equal(t, tc.expectedStatus, statusCode, "Status code should match expected")
+			})
+		}
+	})
+
+	resp, statusCode, err := client.IssueToken(&model.APIToken{UserID: userID})
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Issuing token should succeed")
+	issued := resp.(model.IssuedToken)
+	userClient := client.WithToken(issued.Secret)
+
+	t.Run("Only the secret hash is stored", func(t *testing.T) {
+		tokenHash, err := dbVerifier.GetTokenHash(ctx, issued.Token.TokenID)
+		require.NoError(t, err, "Getting token from database should not fail")
+		sum := sha256.Sum256([]byte(issued.Secret))
+		assert.Equal(t, hex.EncodeToString(sum[:]), tokenHash, "Stored hash should match the secret")
+		assert.Equal(t, userID, issued.Token.UserID, "Token owner should match")
+	})
+
+	t.Run("Issued token authenticates", func(t *testing.T) {
+		_, statusCode, err := userClient.GetTeam(teamName)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusOK, statusCode, "Request with issued token should succeed")
+	})
+
+	t.Run("Only admins issue tokens", func(t *testing.T) {
+		_, statusCode, err := userClient.IssueToken(&model.APIToken{ServiceAccount: "ci"})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Non-admin should not issue tokens")
+
+		_, statusCode, err = userClient.RevokeToken(issued.Token.TokenID)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Non-admin should not revoke tokens")
+	})
+
+	t.Run("Team admin role is limited to the team", func(t *testing.T) {
+		resp, statusCode, err := client.IssueToken(&model.APIToken{UserID: teamAdminID})
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "Issuing token should succeed")
+		teamAdminClient := client.WithToken(resp.(model.IssuedToken).Secret)
+
+		_, statusCode, err = teamAdminClient.IssueToken(&model.APIToken{UserID: teamAdminID, IsAdmin: true})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Team admin should not issue tokens")
+
+		_, statusCode, err = teamAdminClient.AddMembers(teamName, []model.TeamMember{
+			{UserID: fmt.Sprintf("auth-added-%d", timestamp), Username: "Auth Added", IsActive: true},
+		})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusOK, statusCode, "Team admin should manage their own team")
+	})
+
+	t.Run("Revoked token is rejected", func(t *testing.T) {
+		resp, statusCode, err := client.RevokeToken(issued.Token.TokenID)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Revoking token should succeed")
+		tokenMap, ok := resp.(map[string]interface{})["token"].(map[string]interface{})
+		require.True(t, ok, "Response should have 'token' field")
+		assert.NotEmpty(t, tokenMap["revoked_at"], "Token should be revoked")
+
+		_, statusCode, err = userClient.GetTeam(teamName)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusUnauthorized, statusCode, "Revoked token should be rejected")
+
+		_, statusCode, err = client.RevokeToken(fmt.Sprintf("auth-missing-%d", timestamp))
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusNotFound, statusCode, "Unknown token should not be found")
+	})
+}
+
+func TestAuthorization(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("authz-team-%d", timestamp)
+	authorID := fmt.Sprintf("authz-author-%d", timestamp)
+	leadID := fmt.Sprintf("authz-lead-%d", timestamp)
+	firstMemberID := fmt.Sprintf("authz-member-first-%d", timestamp)
+	secondMemberID := fmt.Sprintf("authz-member-second-%d", timestamp)
+
+	_, statusCode, err := client.AddTeam(&model.Team{
+		TeamName:          teamName,
+		ReviewersRequired: 1,
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "Authz Author", IsActive: true},
+			{UserID: leadID, Username: "Authz Lead", IsActive: true, Role: model.RoleLead},
+			{UserID: firstMemberID, Username: "Authz First Member", IsActive: true},
+			{UserID: secondMemberID, Username: "Authz Second Member", IsActive: true},
+		},
+	})
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	clients := map[string]*Client{}
+	for _, userID := range []string{authorID, leadID, firstMemberID, secondMemberID} {
+		resp, statusCode, err := client.IssueToken(&model.APIToken{UserID: userID})
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "Issuing token should succeed")
+		clients[userID] = client.WithToken(resp.(model.IssuedToken).Secret)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("Only the author merges and closes", func(t *testing.T) {
+		prID := fmt.Sprintf("authz-pr-close-%d", timestamp)
+		_, statusCode, err := client.CreatePR(prID, "Authz PR", authorID)
+		require.NoError(t, err, "Creating PR should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+		resp, statusCode, err := clients[firstMemberID].MergePR(prID)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusForbidden, statusCode, "Only the author should merge")
+		errorMap, ok := resp.(map[string]interface{})["error"].(map[string]interface{})
+		require.True(t, ok, "Error response should have 'error' field")
+		assert.Equal(t, model.CodeForbidden, errorMap["code"], "Error code should match expected")
+
+		_, statusCode, err = clients[leadID].ClosePR(prID)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Only the author should close")
+
+		_, statusCode, err = clients[authorID].ClosePR(prID)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusOK, statusCode, "Author should close the PR")
+
+		_, statusCode, err = clients[firstMemberID].ReopenPR(prID)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Only the author should reopen")
+
+		_, statusCode, err = clients[authorID].ReopenPR(prID)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusOK, statusCode, "Author should reopen the PR")
+	})
+
+	t.Run("Only the author marks a draft ready", func(t *testing.T) {
+		prID := fmt.Sprintf("authz-pr-draft-%d", timestamp)
+		_, statusCode, err := client.CreateDraftPR(prID, "Authz Draft PR", authorID)
+		require.NoError(t, err, "Creating PR should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+		_, statusCode, err = clients[firstMemberID].MarkReady(prID)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Only the author should mark the draft ready")
+
+		_, statusCode, err = clients[authorID].MarkReady(prID)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusOK, statusCode, "Author should mark the draft ready")
+	})
+
+	t.Run("Reviewers reassign only themselves", func(t *testing.T) {
+		prID := fmt.Sprintf("authz-pr-reassign-%d", timestamp)
+		_, statusCode, err := client.CreatePR(prID, "Authz PR", authorID)
+		require.NoError(t, err, "Creating PR should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+		dbPR, err := dbVerifier.GetPullRequest(ctx, prID)
+		require.NoError(t, err, "Getting PR from database should not fail")
+		require.Len(t, dbPR.AssignedReviewers, 1, "One reviewer should be assigned")
+		reviewerID := dbPR.AssignedReviewers[0].UserID
+
+		for userID, userClient := range clients {
+			if userID == reviewerID {
+				continue
+			}
+			_, statusCode, err = userClient.ReassignPR(prID, reviewerID)
+			require.NoError(t, err, "API call should not fail")
+			assert.Equal(t, http.StatusForbidden, statusCode, "%s should not reassign another reviewer", userID)
+		}
+
+		_, statusCode, err = clients[reviewerID].ReassignPR(prID, reviewerID)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusOK, statusCode, "Reviewer should reassign themselves")
+	})
+
+	t.Run("Reviewers review only for themselves", func(t *testing.T) {
+		prID := fmt.Sprintf("authz-pr-review-%d", timestamp)
+		_, statusCode, err := client.CreatePR(prID, "Authz PR", authorID)
+		require.NoError(t, err, "Creating PR should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+		dbPR, err := dbVerifier.GetPullRequest(ctx, prID)
+		require.NoError(t, err, "Getting PR from database should not fail")
+		require.Len(t, dbPR.AssignedReviewers, 1, "One reviewer should be assigned")
+		reviewerID := dbPR.AssignedReviewers[0].UserID
+
+		_, statusCode, err = clients[authorID].SubmitReview(prID, reviewerID, model.ReviewStateApproved)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Author should not approve on behalf of the reviewer")
+
+		_, statusCode, err = clients[reviewerID].SubmitReview(prID, reviewerID, model.ReviewStateApproved)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusOK, statusCode, "Reviewer should submit their review")
+	})
+
+	t.Run("Only leads manage the team", func(t *testing.T) {
+		newMember := []model.TeamMember{
+			{UserID: fmt.Sprintf("authz-new-member-%d", timestamp), Username: "Authz New Member", IsActive: true},
+		}
+		_, statusCode, err := clients[firstMemberID].AddMembers(teamName, newMember)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Member should not add members")
+
+		_, statusCode, err = clients[leadID].AddMembers(teamName, newMember)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusOK, statusCode, "Lead should add members")
+
+		_, statusCode, err = clients[firstMemberID].DeactivateUsers(teamName, []string{secondMemberID})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Member should not deactivate members")
+	})
+
+	t.Run("Leads manage only their own team", func(t *testing.T) {
+		otherTeamName := fmt.Sprintf("authz-other-team-%d", timestamp)
+		otherMemberID := fmt.Sprintf("authz-other-member-%d", timestamp)
+		_, statusCode, err := client.AddTeam(&model.Team{
+			TeamName: otherTeamName,
+			Members: []model.TeamMember{
+				{UserID: otherMemberID, Username: "Authz Other Member", IsActive: true},
+			},
+		})
+		require.NoError(t, err, "Adding team should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+		_, statusCode, err = clients[leadID].AddMembers(teamName, []model.TeamMember{
+			{UserID: otherMemberID, Username: "Renamed", IsActive: false},
+		})
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Lead should add an existing user")
+
+		dbUser, err := dbVerifier.GetUser(ctx, otherMemberID)
+		require.NoError(t, err, "Getting user from database should not fail")
+		assert.True(t, dbUser.IsActive, "Existing user should stay active")
+		assert.Equal(t, "Authz Other Member", dbUser.Username, "Existing user should keep their name")
+		assert.ElementsMatch(t, []string{teamName, otherTeamName}, dbUser.Teams, "User should be in both teams")
+
+		_, statusCode, err = clients[leadID].RenameTeam(otherTeamName, otherTeamName+"-renamed")
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Lead should not rename another team")
+
+		_, statusCode, err = clients[leadID].DeleteTeam(otherTeamName, true)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Lead should not delete another team")
+
+		exists, err := dbVerifier.VerifyTeamExists(ctx, otherTeamName)
+		require.NoError(t, err, "Checking team should not fail")
+		assert.True(t, exists, "Team should not be deleted")
+	})
+
+	t.Run("Only leads change settings and code owners", func(t *testing.T) {
+		parentTeamName := fmt.Sprintf("authz-parent-team-%d", timestamp)
+		_, statusCode, err := client.AddTeam(&model.Team{
+			TeamName: parentTeamName,
+			Members: []model.TeamMember{
+				{UserID: fmt.Sprintf("authz-parent-member-%d", timestamp), Username: "Authz Parent Member", IsActive: true},
+			},
+		})
+		require.NoError(t, err, "Adding team should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+		approvalsRequired := 0
+		_, statusCode, err = clients[firstMemberID].UpdateTeamSettings(&model.TeamSettings{
+			TeamName:          teamName,
+			ApprovalsRequired: &approvalsRequired,
+		})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Member should not change settings")
+
+		_, statusCode, err = clients[leadID].UpdateTeamSettings(&model.TeamSettings{
+			TeamName:       teamName,
+			ParentTeamName: &parentTeamName,
+		})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Lead should not move the team under a team they don't lead")
+
+		_, statusCode, err = clients[leadID].UpdateTeamSettings(&model.TeamSettings{
+			TeamName:          teamName,
+			ApprovalsRequired: &approvalsRequired,
+		})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusOK, statusCode, "Lead should change settings")
+
+		owners := &model.CodeOwners{
+			TeamName: teamName,
+			Rules:    []model.CodeOwnerRule{{Pattern: "*.go", Users: []string{firstMemberID}}},
+		}
+		_, statusCode, err = clients[firstMemberID].SetCodeOwners(owners)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Member should not change code owners")
+
+		_, statusCode, err = clients[leadID].SetCodeOwners(owners)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusOK, statusCode, "Lead should change code owners")
+	})
+
+	t.Run("Only admins grant roles", func(t *testing.T) {
+		_, statusCode, err := clients[leadID].AddMembers(teamName, []model.TeamMember{
+			{UserID: leadID, Username: "Authz Lead", IsActive: true, Role: model.RoleAdmin},
+		})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Lead should not grant themselves the admin role")
+
+		_, statusCode, err = clients[leadID].AddTeam(&model.Team{
+			TeamName: fmt.Sprintf("authz-own-team-%d", timestamp),
+			Members: []model.TeamMember{
+				{UserID: leadID, Username: "Authz Lead", IsActive: true, Role: model.RoleAdmin},
+			},
+		})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Non-admin should not create teams")
+
+		roles, err := dbVerifier.GetTeamRoles(ctx, leadID)
+		require.NoError(t, err, "Getting roles from database should not fail")
+		assert.Equal(t, map[string]string{teamName: model.RoleLead}, roles, "Roles should not change")
+	})
+
+	t.Run("Only leads change activity of others", func(t *testing.T) {
+		_, statusCode, err := clients[firstMemberID].SetUserIsActive(secondMemberID, false)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Member should not deactivate others")
+
+		_, statusCode, err = clients[firstMemberID].SetUserIsActive(firstMemberID, true)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusOK, statusCode, "User should change their own activity")
+
+		_, statusCode, err = clients[leadID].SetUserIsActive(secondMemberID, true)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusOK, statusCode, "Lead should change activity of team members")
+
+		from := time.Now().Add(24 * time.Hour)
+		_, statusCode, err = clients[firstMemberID].AddAbsence(secondMemberID, from, from.Add(time.Hour))
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Member should not mark others absent")
+
+		_, statusCode, err = clients[leadID].AddAbsence(secondMemberID, from, from.Add(time.Hour))
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusCreated, statusCode, "Lead should mark team members absent")
+	})
+}
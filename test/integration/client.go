@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"time"
 
 	"github.com/karambo3a/avito_test_task/internal/model"
@@ -15,16 +16,26 @@ import (
 
 type Client struct {
 	baseURL string
+	token   string
 	client  *http.Client
 }
 
+// NewClient creates a client authenticated with the admin token from the environment.
 func NewClient(baseURL string) *Client {
 	return &Client{
 		baseURL: baseURL,
+		token:   os.Getenv("ADMIN_TOKEN"),
 		client:  &http.Client{Timeout: 20 * time.Second},
 	}
 }
 
+// WithToken returns a copy of the client sending the given token. An empty token sends no credentials.
+func (c *Client) WithToken(token string) *Client {
+	clone := *c
+	clone.token = token
+	return &clone
+}
+
 func (c *Client) doRequest(method, path string, queryParams url.Values, body interface{}) ([]byte, int, error) {
 	reqURL := c.baseURL + path
 	if len(queryParams) > 0 {
@@ -51,6 +62,9 @@ func (c *Client) doRequest(method, path string, queryParams url.Values, body int
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -89,6 +103,19 @@ func (c *Client) GetTeam(teamName string) (any, int, error) {
 	params := url.Values{}
 	params.Add("team_name", teamName)
 
+	return c.getTeam(params)
+}
+
+func (c *Client) GetTeamWithSubTeams(teamName string) (any, int, error) {
+	params := url.Values{}
+	params.Add("team_name", teamName)
+	params.Add("include_subteams", "true")
+
+	return c.getTeam(params)
+}
+
+func (c *Client) getTeam(params url.Values) (any, int, error) {
+
 	respBody, statusCode, err := c.doRequest(http.MethodGet, "/team/get", params, nil)
 	if err != nil {
 		return nil, statusCode, err
@@ -110,6 +137,129 @@ func (c *Client) GetTeam(teamName string) (any, int, error) {
 	return team, statusCode, nil
 }
 
+func (c *Client) UpdateTeamSettings(settings *model.TeamSettings) (any, int, error) {
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/team/setSettings", nil, settings)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+func (c *Client) AddMembers(teamName string, members []model.TeamMember) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"team_name": teamName,
+		"members":   members,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/team/addMembers", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+func (c *Client) RemoveMember(teamName, userID string) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"team_name": teamName,
+		"user_id":   userID,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/team/removeMember", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+func (c *Client) RenameTeam(teamName, newTeamName string) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"team_name":     teamName,
+		"new_team_name": newTeamName,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/team/rename", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+func (c *Client) DeleteTeam(teamName string, force bool) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"team_name": teamName,
+		"force":     force,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/team/delete", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+func (c *Client) DeactivateUsers(teamName string, userIDs []string) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"team_name": teamName,
+		"user_ids":  userIDs,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/team/deactivateUsers", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+func (c *Client) SetCodeOwners(owners *model.CodeOwners) (any, int, error) {
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/team/setCodeOwners", nil, owners)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
 // User endpoints
 
 func (c *Client) SetUserIsActive(userID string, isActive bool) (any, int, error) {
@@ -148,6 +298,66 @@ func (c *Client) GetUserReview(userID string) (any, int, error) {
 	return result, statusCode, nil
 }
 
+func (c *Client) MoveUser(userID, teamName string, reassignReviews bool) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"user_id":          userID,
+		"team_name":        teamName,
+		"reassign_reviews": reassignReviews,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/users/moveTeam", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+func (c *Client) AddAbsence(userID string, from, to time.Time) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"user_id": userID,
+		"from":    from.Format(time.RFC3339),
+		"to":      to.Format(time.RFC3339),
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/users/addAbsence", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+func (c *Client) SetUserIsActiveWithReassign(userID string, isActive, reassignReviews bool) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"user_id":          userID,
+		"is_active":        isActive,
+		"reassign_reviews": reassignReviews,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/users/setIsActive", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
 // Pull Request endpoints
 
 func (c *Client) CreatePR(pullRequestID, pullRequestName, authorID string) (any, int, error) {
@@ -170,6 +380,69 @@ func (c *Client) CreatePR(pullRequestID, pullRequestName, authorID string) (any,
 	return result, statusCode, nil
 }
 
+func (c *Client) CreatePRInTeam(pullRequestID, pullRequestName, authorID, teamName string) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"pull_request_id":   pullRequestID,
+		"pull_request_name": pullRequestName,
+		"author_id":         authorID,
+		"team_name":         teamName,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/pullRequest/create", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+func (c *Client) CreatePRWithChangedFiles(pullRequestID, pullRequestName, authorID string, changedFiles []string) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"pull_request_id":   pullRequestID,
+		"pull_request_name": pullRequestName,
+		"author_id":         authorID,
+		"changed_files":     changedFiles,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/pullRequest/create", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+func (c *Client) CreateDraftPR(pullRequestID, pullRequestName, authorID string) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"pull_request_id":   pullRequestID,
+		"pull_request_name": pullRequestName,
+		"author_id":         authorID,
+		"draft":             true,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/pullRequest/create", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
 func (c *Client) MergePR(pullRequestID string) (any, int, error) {
 	reqBody := map[string]interface{}{
 		"pull_request_id": pullRequestID,
@@ -188,6 +461,135 @@ func (c *Client) MergePR(pullRequestID string) (any, int, error) {
 	return result, statusCode, nil
 }
 
+func (c *Client) ForceMergePR(pullRequestID string) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"pull_request_id": pullRequestID,
+		"force":           true,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/pullRequest/merge", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+func (c *Client) MarkReady(pullRequestID string) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"pull_request_id": pullRequestID,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/pullRequest/markReady", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+func (c *Client) ClosePR(pullRequestID string) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"pull_request_id": pullRequestID,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/pullRequest/close", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+func (c *Client) ReopenPR(pullRequestID string) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"pull_request_id": pullRequestID,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/pullRequest/reopen", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+func (c *Client) GetPR(pullRequestID string) (any, int, error) {
+	params := url.Values{}
+	params.Add("pull_request_id", pullRequestID)
+
+	respBody, statusCode, err := c.doRequest(http.MethodGet, "/pullRequest/get", params, nil)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+func (c *Client) GetPRHistory(pullRequestID string) (any, int, error) {
+	params := url.Values{}
+	params.Add("pull_request_id", pullRequestID)
+
+	respBody, statusCode, err := c.doRequest(http.MethodGet, "/pullRequest/history", params, nil)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	if statusCode != http.StatusOK {
+		var errorResp map[string]interface{}
+		if err := json.Unmarshal(respBody, &errorResp); err != nil {
+			return nil, statusCode, fmt.Errorf("failed to parse error response: %w", err)
+		}
+		return errorResp, statusCode, nil
+	}
+
+	var history model.PRHistory
+	if err := json.Unmarshal(respBody, &history); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return history, statusCode, nil
+}
+
+func (c *Client) ListPRs(params url.Values) (any, int, error) {
+	respBody, statusCode, err := c.doRequest(http.MethodGet, "/pullRequest/list", params, nil)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
 func (c *Client) ReassignPR(pullRequestID, oldUserID string) (any, int, error) {
 	reqBody := map[string]interface{}{
 		"pull_request_id": pullRequestID,
@@ -207,6 +609,26 @@ func (c *Client) ReassignPR(pullRequestID, oldUserID string) (any, int, error) {
 	return result, statusCode, nil
 }
 
+func (c *Client) SubmitReview(pullRequestID, reviewerID, state string) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"pull_request_id": pullRequestID,
+		"reviewer_id":     reviewerID,
+		"state":           state,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/pullRequest/review", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
 // Statistics endpoints
 
 func (c *Client) GetUserStatistics(userID string) (any, int, error) {
@@ -259,6 +681,192 @@ func (c *Client) GetTeamStatistics(teamName string) (any, int, error) {
 	return stats, statusCode, nil
 }
 
+// Auth endpoints
+
+func (c *Client) IssueToken(token *model.APIToken) (any, int, error) {
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/auth/issueToken", nil, token)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	if statusCode != http.StatusCreated {
+		var errorResp map[string]interface{}
+		if err := json.Unmarshal(respBody, &errorResp); err != nil {
+			return nil, statusCode, fmt.Errorf("failed to parse error response: %w", err)
+		}
+		return errorResp, statusCode, nil
+	}
+
+	var issued model.IssuedToken
+	if err := json.Unmarshal(respBody, &issued); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return issued, statusCode, nil
+}
+
+func (c *Client) RevokeToken(tokenID string) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"token_id": tokenID,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/auth/revokeToken", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+// Audit endpoints
+
+func (c *Client) ListAuditEvents(params url.Values) (any, int, error) {
+	respBody, statusCode, err := c.doRequest(http.MethodGet, "/audit/events", params, nil)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	if statusCode != http.StatusOK {
+		var errorResp map[string]interface{}
+		if err := json.Unmarshal(respBody, &errorResp); err != nil {
+			return nil, statusCode, fmt.Errorf("failed to parse error response: %w", err)
+		}
+		return errorResp, statusCode, nil
+	}
+
+	var page model.AuditListPage
+	if err := json.Unmarshal(respBody, &page); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return page, statusCode, nil
+}
+
+// Webhook endpoints
+
+func (c *Client) Subscribe(subscription *model.WebhookSubscription) (any, int, error) {
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/webhooks/subscribe", nil, subscription)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	if statusCode != http.StatusCreated {
+		var errorResp map[string]interface{}
+		if err := json.Unmarshal(respBody, &errorResp); err != nil {
+			return nil, statusCode, fmt.Errorf("failed to parse error response: %w", err)
+		}
+		return errorResp, statusCode, nil
+	}
+
+	var result struct {
+		Subscription model.WebhookSubscription `json:"subscription"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.Subscription, statusCode, nil
+}
+
+func (c *Client) ListSubscriptions() (any, int, error) {
+	respBody, statusCode, err := c.doRequest(http.MethodGet, "/webhooks/list", nil, nil)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	if statusCode != http.StatusOK {
+		var errorResp map[string]interface{}
+		if err := json.Unmarshal(respBody, &errorResp); err != nil {
+			return nil, statusCode, fmt.Errorf("failed to parse error response: %w", err)
+		}
+		return errorResp, statusCode, nil
+	}
+
+	var result struct {
+		Subscriptions []model.WebhookSubscription `json:"subscriptions"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.Subscriptions, statusCode, nil
+}
+
+func (c *Client) Unsubscribe(subscriptionID string) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"subscription_id": subscriptionID,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/webhooks/unsubscribe", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, statusCode, nil
+}
+
+func (c *Client) ListDeliveries(params url.Values) (any, int, error) {
+	respBody, statusCode, err := c.doRequest(http.MethodGet, "/webhooks/deliveries", params, nil)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	if statusCode != http.StatusOK {
+		var errorResp map[string]interface{}
+		if err := json.Unmarshal(respBody, &errorResp); err != nil {
+			return nil, statusCode, fmt.Errorf("failed to parse error response: %w", err)
+		}
+		return errorResp, statusCode, nil
+	}
+
+	var result struct {
+		Deliveries []model.WebhookDelivery `json:"deliveries"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.Deliveries, statusCode, nil
+}
+
+func (c *Client) ReplayDelivery(deliveryID int64) (any, int, error) {
+	reqBody := map[string]interface{}{
+		"delivery_id": deliveryID,
+	}
+
+	respBody, statusCode, err := c.doRequest(http.MethodPost, "/webhooks/replay", nil, reqBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	if statusCode != http.StatusOK {
+		var errorResp map[string]interface{}
+		if err := json.Unmarshal(respBody, &errorResp); err != nil {
+			return nil, statusCode, fmt.Errorf("failed to parse error response: %w", err)
+		}
+		return errorResp, statusCode, nil
+	}
+
+	var result struct {
+		Delivery model.WebhookDelivery `json:"delivery"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, statusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.Delivery, statusCode, nil
+}
+
 func toReader(data any) (io.Reader, error) {
 	jsonBytes, err := json.Marshal(data)
 	if err != nil {
@@ -75,9 +75,10 @@ func (v *DBVerifier) GetTeam(ctx context.Context, teamName string) (*model.Team,
 	}
 
 	query := `
-		SELECT u.user_id, u.username, u.is_active
-		FROM users u
-		WHERE u.team_name = $1
+		SELECT u.user_id, u.username, u.is_active, tm.role
+		FROM team_member tm
+		JOIN users u ON u.user_id = tm.user_id
+		WHERE tm.team_name = $1
 	`
 
 	rows, err := v.db.QueryContext(ctx, query, teamName)
@@ -89,7 +90,7 @@ func (v *DBVerifier) GetTeam(ctx context.Context, teamName string) (*model.Team,
 	var members []model.TeamMember
 	for rows.Next() {
 		var member model.TeamMember
-		if err := rows.Scan(&member.UserID, &member.Username, &member.IsActive); err != nil {
+		if err := rows.Scan(&member.UserID, &member.Username, &member.IsActive, &member.Role); err != nil {
 			return nil, fmt.Errorf("failed to scan team member: %w", err)
 		}
 		members = append(members, member)
@@ -99,9 +100,16 @@ func (v *DBVerifier) GetTeam(ctx context.Context, teamName string) (*model.Team,
 		return nil, fmt.Errorf("error iterating team members: %w", err)
 	}
 
+	var parentTeamName sql.NullString
+	parentQuery := `SELECT parent_team_name FROM team WHERE team_name = $1`
+	if err := v.db.QueryRowContext(ctx, parentQuery, teamName).Scan(&parentTeamName); err != nil {
+		return nil, fmt.Errorf("failed to get parent team: %w", err)
+	}
+
 	return &model.Team{
-		TeamName: teamName,
-		Members:  members,
+		TeamName:       teamName,
+		ParentTeamName: parentTeamName.String,
+		Members:        members,
 	}, nil
 }
 
@@ -118,11 +126,11 @@ func (v *DBVerifier) VerifyUserExists(ctx context.Context, userID string) (bool,
 }
 
 func (v *DBVerifier) GetUser(ctx context.Context, userID string) (*model.User, error) {
-	query := `SELECT user_id, username, team_name, is_active FROM users WHERE user_id = $1`
+	query := `SELECT user_id, username, is_active FROM users WHERE user_id = $1`
 
 	var user model.User
 	err := v.db.QueryRowContext(ctx, query, userID).Scan(
-		&user.UserID, &user.Username, &user.TeamName, &user.IsActive,
+		&user.UserID, &user.Username, &user.IsActive,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -131,9 +139,64 @@ func (v *DBVerifier) GetUser(ctx context.Context, userID string) (*model.User, e
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
+	user.Teams, err = v.getUserTeams(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(user.Teams) == 1 {
+		user.TeamName = user.Teams[0]
+	}
+
 	return &user, nil
 }
 
+func (v *DBVerifier) getUserTeams(ctx context.Context, userID string) ([]string, error) {
+	rows, err := v.db.QueryContext(ctx, `SELECT team_name FROM team_member WHERE user_id = $1 ORDER BY team_name`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams := []string{}
+	for rows.Next() {
+		var teamName string
+		if err := rows.Scan(&teamName); err != nil {
+			return nil, fmt.Errorf("failed to scan user team: %w", err)
+		}
+		teams = append(teams, teamName)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user teams: %w", err)
+	}
+
+	return teams, nil
+}
+
+// GetTeamRoles returns the user's role in each of their teams.
+func (v *DBVerifier) GetTeamRoles(ctx context.Context, userID string) (map[string]string, error) {
+	rows, err := v.db.QueryContext(ctx, `SELECT team_name, role FROM team_member WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query team roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := map[string]string{}
+	for rows.Next() {
+		var teamName, role string
+		if err := rows.Scan(&teamName, &role); err != nil {
+			return nil, fmt.Errorf("failed to scan team role: %w", err)
+		}
+		roles[teamName] = role
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team roles: %w", err)
+	}
+
+	return roles, nil
+}
+
 func (v *DBVerifier) VerifyPullRequestExists(ctx context.Context, prID string) (bool, error) {
 	var exists bool
 	query := `SELECT EXISTS(SELECT 1 FROM pr WHERE pr_id = $1)`
@@ -175,7 +238,7 @@ func (v *DBVerifier) GetPullRequest(ctx context.Context, prID string) (*model.Pu
 	}
 
 	reviewersQuery := `
-		SELECT user_id FROM reviewer_x_pr WHERE pr_id = $1
+		SELECT user_id, state, is_fallback FROM reviewer_x_pr WHERE pr_id = $1
 	`
 
 	rows, err := v.db.QueryContext(ctx, reviewersQuery, prID)
@@ -184,13 +247,13 @@ func (v *DBVerifier) GetPullRequest(ctx context.Context, prID string) (*model.Pu
 	}
 	defer rows.Close()
 
-	var reviewers []string
+	var reviewers []model.Reviewer
 	for rows.Next() {
-		var userID string
-		if err := rows.Scan(&userID); err != nil {
+		var reviewer model.Reviewer
+		if err := rows.Scan(&reviewer.UserID, &reviewer.State, &reviewer.IsFallback); err != nil {
 			return nil, fmt.Errorf("failed to scan reviewer: %w", err)
 		}
-		reviewers = append(reviewers, userID)
+		reviewers = append(reviewers, reviewer)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -212,17 +275,25 @@ func (v *DBVerifier) GetUserStatistics(ctx context.Context, userID string) (*mod
 	}
 
 	userQuery := `
-		SELECT user_id, username, team_name FROM users WHERE user_id = $1
+		SELECT user_id, username FROM users WHERE user_id = $1
 	`
 
 	var stats model.UserStatistics
 	err = v.db.QueryRowContext(ctx, userQuery, userID).Scan(
-		&stats.UserID, &stats.Username, &stats.TeamName,
+		&stats.UserID, &stats.Username,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
 
+	stats.Teams, err = v.getUserTeams(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(stats.Teams) == 1 {
+		stats.TeamName = stats.Teams[0]
+	}
+
 	authoredQuery := `
 		SELECT COUNT(*) FROM pr WHERE author_id = $1
 	`
@@ -256,67 +327,132 @@ func (v *DBVerifier) GetTeamStatistics(ctx context.Context, teamName string) (*m
 	var stats model.TeamStatistics
 	stats.TeamName = teamName
 
-	membersQuery := `
-		SELECT user_id FROM users WHERE team_name = $1
+	teamsQuery := `
+		WITH RECURSIVE subtree AS (
+			SELECT team_name FROM team WHERE team_name = $1
+			UNION
+			SELECT t.team_name FROM team AS t JOIN subtree AS s ON t.parent_team_name = s.team_name
+		)
+		SELECT team_name FROM subtree
 	`
 
-	rows, err := v.db.QueryContext(ctx, membersQuery, teamName)
+	rows, err := v.db.QueryContext(ctx, teamsQuery, teamName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query team members: %w", err)
+		return nil, fmt.Errorf("failed to get sub-teams: %w", err)
 	}
 	defer rows.Close()
 
-	var memberIDs []string
+	teamNames := []string{}
 	for rows.Next() {
-		var userID string
-		if err = rows.Scan(&userID); err != nil {
-			return nil, fmt.Errorf("failed to scan member ID: %w", err)
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan sub-team: %w", err)
+		}
+		teamNames = append(teamNames, name)
+		if name != teamName {
+			stats.SubTeams = append(stats.SubTeams, name)
 		}
-		memberIDs = append(memberIDs, userID)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating member IDs: %w", err)
 	}
-
-	if len(memberIDs) == 0 {
-		return &stats, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get sub-teams: %w", err)
 	}
 
 	totalQuery := `
-		SELECT COUNT(*) FROM pr WHERE author_id IN (
-			SELECT user_id FROM users WHERE team_name = $1
-		)
+		SELECT COUNT(*) FROM pr WHERE team_name = ANY($1)
 	`
 
-	err = v.db.QueryRowContext(ctx, totalQuery, teamName).Scan(&stats.TotalPRs)
+	err = v.db.QueryRowContext(ctx, totalQuery, teamNames).Scan(&stats.TotalPRs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count total PRs: %w", err)
 	}
 
 	mergedQuery := `
 		SELECT COUNT(*) FROM pr
-		WHERE author_id IN (
-			SELECT user_id FROM users WHERE team_name = $1
-		) AND status = 'MERGED'
+		WHERE team_name = ANY($1) AND status = 'MERGED'
 	`
 
-	err = v.db.QueryRowContext(ctx, mergedQuery, teamName).Scan(&stats.MergedPRs)
+	err = v.db.QueryRowContext(ctx, mergedQuery, teamNames).Scan(&stats.MergedPRs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count merged PRs: %w", err)
 	}
 
 	openQuery := `
 		SELECT COUNT(*) FROM pr
-		WHERE author_id IN (
-			SELECT user_id FROM users WHERE team_name = $1
-		) AND status = 'OPEN'
+		WHERE team_name = ANY($1) AND status = 'OPEN'
 	`
 
-	err = v.db.QueryRowContext(ctx, openQuery, teamName).Scan(&stats.OpenPRs)
+	err = v.db.QueryRowContext(ctx, openQuery, teamNames).Scan(&stats.OpenPRs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count open PRs: %w", err)
 	}
 
 	return &stats, nil
 }
+
+func (v *DBVerifier) GetTokenHash(ctx context.Context, tokenID string) (string, error) {
+	var tokenHash string
+	query := `SELECT token_hash FROM api_token WHERE token_id = $1`
+
+	err := v.db.QueryRowContext(ctx, query, tokenID).Scan(&tokenHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to get token hash: %w", err)
+	}
+
+	return tokenHash, nil
+}
+
+func (v *DBVerifier) CountAuditEvents(ctx context.Context, entityType, entityID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM audit_event WHERE entity_type = $1 AND entity_id = $2`
+
+	err := v.db.QueryRowContext(ctx, query, entityType, entityID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+
+	return count, nil
+}
+
+// DeleteAuditEvents tries to remove events of the entity, the audit log is expected to refuse it.
+func (v *DBVerifier) DeleteAuditEvents(ctx context.Context, entityType, entityID string) error {
+	query := `DELETE FROM audit_event WHERE entity_type = $1 AND entity_id = $2`
+
+	_, err := v.db.ExecContext(ctx, query, entityType, entityID)
+	return err
+}
+
+func (v *DBVerifier) CountWebhookDeliveries(ctx context.Context, subscriptionID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM webhook_delivery WHERE subscription_id = $1`
+
+	err := v.db.QueryRowContext(ctx, query, subscriptionID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountOutboxEvents counts the outbox events about the PR, published or not.
+func (v *DBVerifier) CountOutboxEvents(ctx context.Context, pullRequestID string, published bool) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM outbox_event
+		WHERE data->>'pull_request_id' = $1 AND (published_at IS NOT NULL) = $2
+		`
+
+	err := v.db.QueryRowContext(ctx, query, pullRequestID, published).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count outbox events: %w", err)
+	}
+
+	return count, nil
+}
+
+func reviewerIDs(reviewers []model.Reviewer) []string {
+	ids := make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		ids = append(ids, reviewer.UserID)
+	}
+	return ids
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"slices"
 	"testing"
 	"time"
 
@@ -359,7 +361,7 @@ func TestReassignPR(t *testing.T) {
 	require.NoError(t, err, "Getting PR from database should not fail")
 	require.NotEmpty(t, dbPR.AssignedReviewers, "PR should have assigned reviewers")
 
-	oldReviewerID := dbPR.AssignedReviewers[0]
+	oldReviewerID := dbPR.AssignedReviewers[0].UserID
 
 	testCases := []struct {
 		name           string
@@ -435,9 +437,7 @@ func TestReassignPR(t *testing.T) {
 				reviewers, ok := prMap["assigned_reviewers"].([]interface{})
 				require.True(t, ok, "Assigned reviewers should be an array")
 
-				for _, reviewer := range reviewers {
-					assert.NotEqual(t, tc.oldUserID, reviewer, "Old reviewer should not be in the list")
-				}
+				assert.NotContains(t, responseReviewerIDs(reviewers), tc.oldUserID, "Old reviewer should not be in the list")
 
 				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 				defer cancel()
@@ -445,18 +445,8 @@ func TestReassignPR(t *testing.T) {
 				dbPR, err := dbVerifier.GetPullRequest(ctx, tc.prID)
 				assert.NoError(t, err, "Getting PR from database should not fail")
 
-				for _, reviewer := range dbPR.AssignedReviewers {
-					assert.NotEqual(t, tc.oldUserID, reviewer, "Old reviewer should not be in the database")
-				}
-
-				newReviewerFound := false
-				for _, reviewer := range dbPR.AssignedReviewers {
-					if reviewer == replacedBy {
-						newReviewerFound = true
-						break
-					}
-				}
-				assert.True(t, newReviewerFound, "New reviewer should be in the database")
+				assert.NotContains(t, reviewerIDs(dbPR.AssignedReviewers), tc.oldUserID, "Old reviewer should not be in the database")
+				assert.Contains(t, reviewerIDs(dbPR.AssignedReviewers), replacedBy, "New reviewer should be in the database")
 
 			case http.StatusNotFound, http.StatusBadRequest, http.StatusConflict:
 				respData, ok := resp.(map[string]interface{})
@@ -477,3 +467,1154 @@ func TestReassignPR(t *testing.T) {
 		})
 	}
 }
+
+func TestReviewerLoadBalancing(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("balance-test-team-%d", timestamp)
+	authorID := fmt.Sprintf("balance-author-%d", timestamp)
+
+	members := []model.TeamMember{
+		{
+			UserID:   authorID,
+			Username: "Balance Author",
+			IsActive: true,
+		},
+	}
+	for i := 1; i <= 5; i++ {
+		members = append(members, model.TeamMember{
+			UserID:   fmt.Sprintf("balance-reviewer-%d-%d", timestamp, i),
+			Username: fmt.Sprintf("Balance Reviewer %d", i),
+			IsActive: true,
+		})
+	}
+
+	_, statusCode, err := client.AddTeam(&model.Team{TeamName: teamName, Members: members})
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	firstPRID := fmt.Sprintf("balance-pr-%d-1", timestamp)
+	secondPRID := fmt.Sprintf("balance-pr-%d-2", timestamp)
+
+	_, statusCode, err = client.CreatePR(firstPRID, "Balance PR 1", authorID)
+	require.NoError(t, err, "Creating PR should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+	_, statusCode, err = client.CreatePR(secondPRID, "Balance PR 2", authorID)
+	require.NoError(t, err, "Creating PR should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	firstPR, err := dbVerifier.GetPullRequest(ctx, firstPRID)
+	require.NoError(t, err, "Getting PR from database should not fail")
+	require.Len(t, firstPR.AssignedReviewers, 2, "First PR should have 2 reviewers")
+
+	secondPR, err := dbVerifier.GetPullRequest(ctx, secondPRID)
+	require.NoError(t, err, "Getting PR from database should not fail")
+	require.Len(t, secondPR.AssignedReviewers, 2, "Second PR should have 2 reviewers")
+
+	for _, reviewer := range reviewerIDs(secondPR.AssignedReviewers) {
+		assert.NotContains(t, reviewerIDs(firstPR.AssignedReviewers), reviewer, "Second PR should go to the least loaded reviewers")
+	}
+
+	busy := append(reviewerIDs(firstPR.AssignedReviewers), reviewerIDs(secondPR.AssignedReviewers)...)
+	var idleReviewerID string
+	for _, member := range members[1:] {
+		if !slices.Contains(busy, member.UserID) {
+			idleReviewerID = member.UserID
+		}
+	}
+	require.NotEmpty(t, idleReviewerID, "One reviewer should have no open reviews")
+
+	resp, statusCode, err := client.ReassignPR(firstPRID, firstPR.AssignedReviewers[0].UserID)
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "PR reassignment should succeed")
+
+	respData, ok := resp.(map[string]interface{})
+	require.True(t, ok, "Response should be a map")
+	assert.Equal(t, idleReviewerID, respData["replaced_by"], "Reassign should pick the reviewer without open reviews")
+}
+
+func TestRoundRobinAssignment(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("round-robin-team-%d", timestamp)
+	authorID := fmt.Sprintf("round-robin-author-%d", timestamp)
+	reviewer1ID := fmt.Sprintf("round-robin-reviewer-%d-1", timestamp)
+	reviewer2ID := fmt.Sprintf("round-robin-reviewer-%d-2", timestamp)
+	reviewer3ID := fmt.Sprintf("round-robin-reviewer-%d-3", timestamp)
+
+	team := &model.Team{
+		TeamName:           teamName,
+		AssignmentStrategy: model.StrategyRoundRobin,
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "Round Robin Author", IsActive: true},
+			{UserID: reviewer1ID, Username: "Round Robin Reviewer 1", IsActive: true},
+			{UserID: reviewer2ID, Username: "Round Robin Reviewer 2", IsActive: true},
+			{UserID: reviewer3ID, Username: "Round Robin Reviewer 3", IsActive: true},
+		},
+	}
+
+	_, statusCode, err := client.AddTeam(team)
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	firstPRID := fmt.Sprintf("round-robin-pr-%d-1", timestamp)
+	secondPRID := fmt.Sprintf("round-robin-pr-%d-2", timestamp)
+
+	_, statusCode, err = client.CreatePR(firstPRID, "Round Robin PR 1", authorID)
+	require.NoError(t, err, "Creating PR should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+	_, statusCode, err = client.CreatePR(secondPRID, "Round Robin PR 2", authorID)
+	require.NoError(t, err, "Creating PR should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	firstPR, err := dbVerifier.GetPullRequest(ctx, firstPRID)
+	require.NoError(t, err, "Getting PR from database should not fail")
+	assert.ElementsMatch(t, []string{reviewer1ID, reviewer2ID}, reviewerIDs(firstPR.AssignedReviewers), "Never assigned reviewers should go first")
+
+	secondPR, err := dbVerifier.GetPullRequest(ctx, secondPRID)
+	require.NoError(t, err, "Getting PR from database should not fail")
+	assert.ElementsMatch(t, []string{reviewer3ID, reviewer1ID}, reviewerIDs(secondPR.AssignedReviewers), "Least recently assigned reviewers should go next")
+}
+
+func TestFallbackReviewers(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	partnerTeamName := fmt.Sprintf("fallback-partner-team-%d", timestamp)
+	homeTeamName := fmt.Sprintf("fallback-home-team-%d", timestamp)
+	authorID := fmt.Sprintf("fallback-author-%d", timestamp)
+	partner1ID := fmt.Sprintf("fallback-partner-%d-1", timestamp)
+	partner2ID := fmt.Sprintf("fallback-partner-%d-2", timestamp)
+	prID := fmt.Sprintf("fallback-pr-%d", timestamp)
+
+	partnerTeam := &model.Team{
+		TeamName: partnerTeamName,
+		Members: []model.TeamMember{
+			{UserID: partner1ID, Username: "Partner 1", IsActive: true},
+			{UserID: partner2ID, Username: "Partner 2", IsActive: true},
+		},
+	}
+	_, statusCode, err := client.AddTeam(partnerTeam)
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	homeTeam := &model.Team{
+		TeamName:      homeTeamName,
+		FallbackTeams: []string{partnerTeamName},
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "Lonely Author", IsActive: true},
+			{UserID: fmt.Sprintf("fallback-inactive-%d", timestamp), Username: "Inactive Teammate", IsActive: false},
+		},
+	}
+	_, statusCode, err = client.AddTeam(homeTeam)
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	resp, statusCode, err := client.CreatePR(prID, "Fallback PR", authorID)
+	require.NoError(t, err, "Creating PR should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+	respData, ok := resp.(map[string]interface{})
+	require.True(t, ok, "Response should be a map")
+
+	prMap, ok := respData["pr"].(map[string]interface{})
+	require.True(t, ok, "Response should have 'pr' field")
+
+	reviewers, ok := prMap["assigned_reviewers"].([]interface{})
+	require.True(t, ok, "Assigned reviewers should be an array")
+	assert.ElementsMatch(t, []string{partner1ID, partner2ID}, responseReviewerIDs(reviewers), "Reviewers should come from the fallback team")
+	for _, reviewer := range reviewers {
+		reviewerMap, ok := reviewer.(map[string]interface{})
+		require.True(t, ok, "Reviewer should be an object")
+		assert.Equal(t, true, reviewerMap["is_fallback"], "Reviewer should be marked as fallback")
+	}
+
+	_, statusCode, err = client.AddTeam(&model.Team{
+		TeamName:      fmt.Sprintf("fallback-missing-team-%d", timestamp),
+		FallbackTeams: []string{"non-existent-team"},
+		Members: []model.TeamMember{
+			{UserID: fmt.Sprintf("fallback-missing-user-%d", timestamp), Username: "Missing Fallback", IsActive: true},
+		},
+	})
+	require.NoError(t, err, "API call should not fail")
+	assert.Equal(t, http.StatusNotFound, statusCode, "Unknown fallback team should not be accepted")
+}
+
+func TestCodeOwnerAssignment(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("owners-team-%d", timestamp)
+	docsTeamName := fmt.Sprintf("owners-docs-team-%d", timestamp)
+	authorID := fmt.Sprintf("owners-author-%d", timestamp)
+	goOwnerID := fmt.Sprintf("owners-go-owner-%d", timestamp)
+	docsOwnerID := fmt.Sprintf("owners-docs-owner-%d", timestamp)
+
+	members := []model.TeamMember{
+		{UserID: authorID, Username: "Owners Author", IsActive: true},
+		{UserID: goOwnerID, Username: "Go Owner", IsActive: true},
+	}
+	for i := 1; i <= 3; i++ {
+		members = append(members, model.TeamMember{
+			UserID:   fmt.Sprintf("owners-reviewer-%d-%d", timestamp, i),
+			Username: fmt.Sprintf("Owners Reviewer %d", i),
+			IsActive: true,
+		})
+	}
+
+	_, statusCode, err := client.AddTeam(&model.Team{TeamName: teamName, Members: members})
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	_, statusCode, err = client.AddTeam(&model.Team{
+		TeamName: docsTeamName,
+		Members: []model.TeamMember{
+			{UserID: docsOwnerID, Username: "Docs Owner", IsActive: true},
+		},
+	})
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	_, statusCode, err = client.SetCodeOwners(&model.CodeOwners{
+		TeamName: teamName,
+		Rules: []model.CodeOwnerRule{
+			{Pattern: "*.go", Users: []string{goOwnerID}},
+			{Pattern: "/docs/", Teams: []string{docsTeamName}},
+		},
+	})
+	require.NoError(t, err, "Setting code owners should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "Setting code owners should succeed")
+
+	testCases := []struct {
+		name          string
+		changedFiles  []string
+		expectedOwner string
+	}{
+		{
+			name:          "Go file owned by user",
+			changedFiles:  []string{"internal/service/service.go"},
+			expectedOwner: goOwnerID,
+		},
+		{
+			name:          "Docs owned by another team",
+			changedFiles:  []string{"docs/api/README.md"},
+			expectedOwner: docsOwnerID,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prID := fmt.Sprintf("owners-pr-%d-%d", timestamp, i)
+			resp, statusCode, err := client.CreatePRWithChangedFiles(prID, tc.name, authorID, tc.changedFiles)
+			require.NoError(t, err, "API call should not fail")
+			require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+			respData, ok := resp.(map[string]interface{})
+			require.True(t, ok, "Response should be a map")
+
+			prMap, ok := respData["pr"].(map[string]interface{})
+			require.True(t, ok, "Response should have 'pr' field")
+
+			reviewers, ok := prMap["assigned_reviewers"].([]interface{})
+			require.True(t, ok, "Assigned reviewers should be an array")
+			assert.Len(t, reviewers, 2, "Remaining slots should be filled by the team strategy")
+			assert.Contains(t, responseReviewerIDs(reviewers), tc.expectedOwner, "Code owner should be assigned")
+		})
+	}
+
+	_, statusCode, err = client.SetCodeOwners(&model.CodeOwners{
+		TeamName: teamName,
+		Rules:    []model.CodeOwnerRule{{Pattern: "*.go"}},
+	})
+	require.NoError(t, err, "API call should not fail")
+	assert.Equal(t, http.StatusBadRequest, statusCode, "Rule without owners should be rejected")
+}
+
+func TestSubmitReview(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("review-test-team-%d", timestamp)
+	authorID := fmt.Sprintf("review-author-%d", timestamp)
+	reviewerID := fmt.Sprintf("review-reviewer-%d", timestamp)
+	prID := fmt.Sprintf("review-pr-%d", timestamp)
+	mergedPRID := fmt.Sprintf("review-merged-pr-%d", timestamp)
+
+	team := &model.Team{
+		TeamName: teamName,
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "Review Author", IsActive: true},
+			{UserID: reviewerID, Username: "Review Reviewer", IsActive: true},
+		},
+	}
+
+	_, statusCode, err := client.AddTeam(team)
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	for _, id := range []string{prID, mergedPRID} {
+		_, statusCode, err = client.CreatePR(id, "Review PR", authorID)
+		require.NoError(t, err, "Creating PR should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+	}
+
+	_, statusCode, err = client.MergePR(mergedPRID)
+	require.NoError(t, err, "Merging PR should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "PR merge should succeed")
+
+	testCases := []struct {
+		name           string
+		prID           string
+		reviewerID     string
+		state          string
+		expectedStatus int
+		errorCode      string
+	}{
+		{
+			name:           "Request changes",
+			prID:           prID,
+			reviewerID:     reviewerID,
+			state:          model.ReviewStateChangesRequested,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Approve",
+			prID:           prID,
+			reviewerID:     reviewerID,
+			state:          model.ReviewStateApproved,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Review by non-assigned user",
+			prID:           prID,
+			reviewerID:     authorID,
+			state:          model.ReviewStateApproved,
+			expectedStatus: http.StatusConflict,
+			errorCode:      model.CodeNotAssigned,
+		},
+		{
+			name:           "Review merged PR",
+			prID:           mergedPRID,
+			reviewerID:     reviewerID,
+			state:          model.ReviewStateApproved,
+			expectedStatus: http.StatusConflict,
+			errorCode:      model.CodePRMerged,
+		},
+		{
+			name:           "Unknown state",
+			prID:           prID,
+			reviewerID:     reviewerID,
+			state:          model.ReviewStatePending,
+			expectedStatus: http.StatusBadRequest,
+			errorCode:      model.CodeInvalidField,
+		},
+		{
+			name:           "Non-existent PR",
+			prID:           "non-existent-pr",
+			reviewerID:     reviewerID,
+			state:          model.ReviewStateApproved,
+			expectedStatus: http.StatusNotFound,
+			errorCode:      model.CodeNotFound,
+		},
+		{
+			name:           "Empty reviewer ID",
+			prID:           prID,
+			state:          model.ReviewStateApproved,
+			expectedStatus: http.StatusBadRequest,
+			errorCode:      model.CodeEmptyField,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, statusCode, err := client.SubmitReview(tc.prID, tc.reviewerID, tc.state)
+			require.NoError(t, err, "API call should not fail")
+			assert.Equal(t, tc.expectedStatus, statusCode, "Status code should match expected")
+
+			respData, ok := resp.(map[string]interface{})
+			require.True(t, ok, "Response should be a map")
+
+			switch statusCode {
+			case http.StatusOK:
+				prMap, ok := respData["pr"].(map[string]interface{})
+				require.True(t, ok, "Response should have 'pr' field")
+
+				reviewers, ok := prMap["assigned_reviewers"].([]interface{})
+				require.True(t, ok, "Assigned reviewers should be an array")
+				require.Len(t, reviewers, 1, "PR should have one reviewer")
+
+				reviewerMap, ok := reviewers[0].(map[string]interface{})
+				require.True(t, ok, "Reviewer should be an object")
+				assert.Equal(t, tc.state, reviewerMap["state"], "Reviewer state should be updated")
+
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				dbPR, err := dbVerifier.GetPullRequest(ctx, tc.prID)
+				require.NoError(t, err, "Getting PR from database should not fail")
+				assert.Equal(t, tc.state, dbPR.AssignedReviewers[0].State, "Reviewer state in database should be updated")
+
+			default:
+				errorMap, ok := respData["error"].(map[string]interface{})
+				require.True(t, ok, "Error response should have 'error' field")
+				assert.Equal(t, tc.errorCode, errorMap["code"], "Error code should match expected")
+			}
+		})
+	}
+}
+
+func TestMergeRequiresApprovals(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("approval-test-team-%d", timestamp)
+	authorID := fmt.Sprintf("approval-author-%d", timestamp)
+	reviewerID := fmt.Sprintf("approval-reviewer-%d", timestamp)
+	approvedPRID := fmt.Sprintf("approval-pr-%d", timestamp)
+	changesPRID := fmt.Sprintf("approval-changes-pr-%d", timestamp)
+
+	team := &model.Team{
+		TeamName:          teamName,
+		ReviewersRequired: 1,
+		ApprovalsRequired: 1,
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "Approval Author", IsActive: true},
+			{UserID: reviewerID, Username: "Approval Reviewer", IsActive: true},
+		},
+	}
+
+	_, statusCode, err := client.AddTeam(team)
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	for _, id := range []string{approvedPRID, changesPRID} {
+		_, statusCode, err = client.CreatePR(id, "Approval PR", authorID)
+		require.NoError(t, err, "Creating PR should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+	}
+
+	resp, statusCode, err := client.MergePR(approvedPRID)
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusConflict, statusCode, "Merge without approvals should fail")
+	errorMap, ok := resp.(map[string]interface{})["error"].(map[string]interface{})
+	require.True(t, ok, "Error response should have 'error' field")
+	assert.Equal(t, model.CodeNotApproved, errorMap["code"], "Error code should match expected")
+
+	_, statusCode, err = client.SubmitReview(approvedPRID, reviewerID, model.ReviewStateApproved)
+	require.NoError(t, err, "Submitting review should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "Review should succeed")
+
+	resp, statusCode, err = client.MergePR(approvedPRID)
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "Merge of approved PR should succeed")
+	prMap, ok := resp.(map[string]interface{})["pr"].(map[string]interface{})
+	require.True(t, ok, "Response should have 'pr' field")
+	assert.Equal(t, false, prMap["force_merged"], "Approved PR should not be force merged")
+
+	_, statusCode, err = client.SubmitReview(changesPRID, reviewerID, model.ReviewStateChangesRequested)
+	require.NoError(t, err, "Submitting review should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "Review should succeed")
+
+	_, statusCode, err = client.MergePR(changesPRID)
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusConflict, statusCode, "Merge with requested changes should fail")
+
+	resp, statusCode, err = client.ForceMergePR(changesPRID)
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "Force merge should succeed")
+	prMap, ok = resp.(map[string]interface{})["pr"].(map[string]interface{})
+	require.True(t, ok, "Response should have 'pr' field")
+	assert.Equal(t, "MERGED", prMap["status"], "PR should be merged")
+	assert.Equal(t, true, prMap["force_merged"], "PR should be marked as force merged")
+	assert.Equal(t, "service:bootstrap", prMap["force_merged_by"], "Admin forcing the merge should be stored")
+}
+
+func TestPRLifecycle(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("lifecycle-test-team-%d", timestamp)
+	authorID := fmt.Sprintf("lifecycle-author-%d", timestamp)
+	reviewerID := fmt.Sprintf("lifecycle-reviewer-%d", timestamp)
+	prID := fmt.Sprintf("lifecycle-pr-%d", timestamp)
+	closedDraftPRID := fmt.Sprintf("lifecycle-closed-draft-pr-%d", timestamp)
+
+	team := &model.Team{
+		TeamName: teamName,
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "Lifecycle Author", IsActive: true},
+			{UserID: reviewerID, Username: "Lifecycle Reviewer", IsActive: true},
+		},
+	}
+
+	_, statusCode, err := client.AddTeam(team)
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	resp, statusCode, err := client.CreateDraftPR(prID, "Lifecycle PR", authorID)
+	require.NoError(t, err, "Creating PR should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+	prMap, ok := resp.(map[string]interface{})["pr"].(map[string]interface{})
+	require.True(t, ok, "Response should have 'pr' field")
+	assert.Equal(t, model.PRStatusDraft, prMap["status"], "PR should be a draft")
+	assert.Empty(t, prMap["assigned_reviewers"], "Draft PR should have no reviewers")
+
+	_, statusCode, err = client.CreateDraftPR(closedDraftPRID, "Lifecycle Closed Draft PR", authorID)
+	require.NoError(t, err, "Creating PR should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+	testCases := []struct {
+		name              string
+		action            func(string) (any, int, error)
+		prID              string
+		expectedStatus    int
+		expectedPRStatus  string
+		expectedReviewers []string
+		errorCode         string
+	}{
+		{
+			name:           "Merge draft",
+			action:         client.MergePR,
+			prID:           prID,
+			expectedStatus: http.StatusConflict,
+			errorCode:      model.CodeInvalidTransition,
+		},
+		{
+			name:           "Reopen draft",
+			action:         client.ReopenPR,
+			prID:           prID,
+			expectedStatus: http.StatusConflict,
+			errorCode:      model.CodeInvalidTransition,
+		},
+		{
+			name:              "Mark ready",
+			action:            client.MarkReady,
+			prID:              prID,
+			expectedStatus:    http.StatusOK,
+			expectedPRStatus:  model.PRStatusOpen,
+			expectedReviewers: []string{reviewerID},
+		},
+		{
+			name:           "Mark ready twice",
+			action:         client.MarkReady,
+			prID:           prID,
+			expectedStatus: http.StatusConflict,
+			errorCode:      model.CodeInvalidTransition,
+		},
+		{
+			name:              "Close",
+			action:            client.ClosePR,
+			prID:              prID,
+			expectedStatus:    http.StatusOK,
+			expectedPRStatus:  model.PRStatusClosed,
+			expectedReviewers: []string{reviewerID},
+		},
+		{
+			name:           "Merge closed",
+			action:         client.MergePR,
+			prID:           prID,
+			expectedStatus: http.StatusConflict,
+			errorCode:      model.CodeInvalidTransition,
+		},
+		{
+			name:              "Reopen",
+			action:            client.ReopenPR,
+			prID:              prID,
+			expectedStatus:    http.StatusOK,
+			expectedPRStatus:  model.PRStatusOpen,
+			expectedReviewers: []string{reviewerID},
+		},
+		{
+			name:              "Merge reopened",
+			action:            client.MergePR,
+			prID:              prID,
+			expectedStatus:    http.StatusOK,
+			expectedPRStatus:  model.PRStatusMerged,
+			expectedReviewers: []string{reviewerID},
+		},
+		{
+			name:           "Close merged",
+			action:         client.ClosePR,
+			prID:           prID,
+			expectedStatus: http.StatusConflict,
+			errorCode:      model.CodeInvalidTransition,
+		},
+		{
+			name:           "Non-existent PR",
+			action:         client.ClosePR,
+			prID:           "non-existent-pr",
+			expectedStatus: http.StatusNotFound,
+			errorCode:      model.CodeNotFound,
+		},
+		{
+			name:           "Empty PR ID",
+			action:         client.ReopenPR,
+			prID:           "",
+			expectedStatus: http.StatusBadRequest,
+			errorCode:      model.CodeEmptyField,
+		},
+		{
+			name:             "Close draft",
+			action:           client.ClosePR,
+			prID:             closedDraftPRID,
+			expectedStatus:   http.StatusOK,
+			expectedPRStatus: model.PRStatusClosed,
+		},
+		{
+			name:             "Reopen closed draft",
+			action:           client.ReopenPR,
+			prID:             closedDraftPRID,
+			expectedStatus:   http.StatusOK,
+			expectedPRStatus: model.PRStatusDraft,
+		},
+		{
+			name:              "Mark reopened draft ready",
+			action:            client.MarkReady,
+			prID:              closedDraftPRID,
+			expectedStatus:    http.StatusOK,
+			expectedPRStatus:  model.PRStatusOpen,
+			expectedReviewers: []string{reviewerID},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, statusCode, err := tc.action(tc.prID)
+			require.NoError(t, err, "API call should not fail")
+			assert.Equal(t, tc.expectedStatus, statusCode, "Status code should match expected")
+
+			respData, ok := resp.(map[string]interface{})
+			require.True(t, ok, "Response should be a map")
+
+			switch statusCode {
+			case http.StatusOK:
+				prMap, ok := respData["pr"].(map[string]interface{})
+				require.True(t, ok, "Response should have 'pr' field")
+				assert.Equal(t, tc.expectedPRStatus, prMap["status"], "PR status should match expected")
+
+				reviewers, ok := prMap["assigned_reviewers"].([]interface{})
+				require.True(t, ok, "Assigned reviewers should be an array")
+				assert.ElementsMatch(t, tc.expectedReviewers, responseReviewerIDs(reviewers), "Reviewers should match expected")
+
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				dbPR, err := dbVerifier.GetPullRequest(ctx, tc.prID)
+				require.NoError(t, err, "Getting PR from database should not fail")
+				assert.Equal(t, tc.expectedPRStatus, dbPR.Status, "PR status in database should match expected")
+
+			default:
+				errorMap, ok := respData["error"].(map[string]interface{})
+				require.True(t, ok, "Error response should have 'error' field")
+				assert.Equal(t, tc.errorCode, errorMap["code"], "Error code should match expected")
+			}
+		})
+	}
+}
+
+func TestGetPR(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("get-test-team-%d", timestamp)
+	authorID := fmt.Sprintf("get-author-%d", timestamp)
+	reviewerID := fmt.Sprintf("get-reviewer-%d", timestamp)
+	prID := fmt.Sprintf("get-pr-%d", timestamp)
+
+	team := &model.Team{
+		TeamName: teamName,
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "Get Author", IsActive: true},
+			{UserID: reviewerID, Username: "Get Reviewer", IsActive: true},
+		},
+	}
+
+	_, statusCode, err := client.AddTeam(team)
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	resp, statusCode, err := client.CreatePR(prID, "Get PR", authorID)
+	require.NoError(t, err, "Creating PR should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+	createdPR := resp.(map[string]interface{})["pr"].(map[string]interface{})
+	createdAt, ok := createdPR["createdAt"].(string)
+	require.True(t, ok, "Created PR should have 'createdAt' field")
+	_, err = time.Parse(time.RFC3339Nano, createdAt)
+	require.NoError(t, err, "createdAt should be a valid timestamp")
+
+	_, statusCode, err = client.MergePR(prID)
+	require.NoError(t, err, "Merging PR should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "PR merge should succeed")
+
+	testCases := []struct {
+		name           string
+		prID           string
+		expectedStatus int
+		errorCode      string
+	}{
+		{
+			name:           "Existing PR",
+			prID:           prID,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Non-existent PR",
+			prID:           "non-existent-pr",
+			expectedStatus: http.StatusNotFound,
+			errorCode:      model.CodeNotFound,
+		},
+		{
+			name:           "Empty PR ID",
+			prID:           "",
+			expectedStatus: http.StatusBadRequest,
+			errorCode:      model.CodeEmptyField,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, statusCode, err := client.GetPR(tc.prID)
+			require.NoError(t, err, "API call should not fail")
+			assert.Equal(t, tc.expectedStatus, statusCode, "Status code should match expected")
+
+			respData, ok := resp.(map[string]interface{})
+			require.True(t, ok, "Response should be a map")
+
+			switch statusCode {
+			case http.StatusOK:
+				prMap, ok := respData["pr"].(map[string]interface{})
+				require.True(t, ok, "Response should have 'pr' field")
+				assert.Equal(t, prID, prMap["pull_request_id"], "PR ID should match")
+				assert.Equal(t, "Get PR", prMap["pull_request_name"], "PR name should match")
+				assert.Equal(t, authorID, prMap["author_id"], "Author ID should match")
+				assert.Equal(t, model.PRStatusMerged, prMap["status"], "PR should be merged")
+				assert.Equal(t, createdAt, prMap["createdAt"], "createdAt should match the creation response")
+				assert.NotEmpty(t, prMap["mergedAt"], "mergedAt should be set")
+
+				reviewers, ok := prMap["assigned_reviewers"].([]interface{})
+				require.True(t, ok, "Assigned reviewers should be an array")
+				assert.Equal(t, []string{reviewerID}, responseReviewerIDs(reviewers), "Reviewers should match")
+
+			default:
+				errorMap, ok := respData["error"].(map[string]interface{})
+				require.True(t, ok, "Error response should have 'error' field")
+				assert.Equal(t, tc.errorCode, errorMap["code"], "Error code should match expected")
+			}
+		})
+	}
+}
+
+func TestPRHistory(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("history-team-%d", timestamp)
+	authorID := fmt.Sprintf("history-author-%d", timestamp)
+	prID := fmt.Sprintf("history-pr-%d", timestamp)
+
+	_, statusCode, err := client.AddTeam(&model.Team{
+		TeamName:          teamName,
+		ReviewersRequired: 1,
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "History Author", IsActive: true},
+			{UserID: fmt.Sprintf("history-first-%d", timestamp), Username: "History First", IsActive: true},
+			{UserID: fmt.Sprintf("history-second-%d", timestamp), Username: "History Second", IsActive: true},
+			{UserID: fmt.Sprintf("history-third-%d", timestamp), Username: "History Third", IsActive: true},
+		},
+	})
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, statusCode, err = client.CreatePR(prID, "History PR", authorID)
+	require.NoError(t, err, "Creating PR should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+	dbPR, err := dbVerifier.GetPullRequest(ctx, prID)
+	require.NoError(t, err, "Getting PR from database should not fail")
+	require.Len(t, dbPR.AssignedReviewers, 1, "One reviewer should be assigned")
+	initialReviewerID := dbPR.AssignedReviewers[0].UserID
+
+	resp, statusCode, err := client.ReassignPR(prID, initialReviewerID)
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "Reassignment should succeed")
+	reassignedReviewerID := resp.(map[string]interface{})["replaced_by"].(string)
+
+	_, statusCode, err = client.DeactivateUsers(teamName, []string{reassignedReviewerID})
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "Deactivation should succeed")
+
+	dbPR, err = dbVerifier.GetPullRequest(ctx, prID)
+	require.NoError(t, err, "Getting PR from database should not fail")
+	require.Len(t, dbPR.AssignedReviewers, 1, "Deactivated reviewer should be replaced")
+	finalReviewerID := dbPR.AssignedReviewers[0].UserID
+
+	_, statusCode, err = client.ForceMergePR(prID)
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "PR merge should succeed")
+
+	t.Run("Assignments keep former reviewers", func(t *testing.T) {
+		resp, statusCode, err := client.GetPRHistory(prID)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Getting history should succeed")
+		history := resp.(model.PRHistory)
+
+		require.Len(t, history.Assignments, 3, "Every assignment should be kept")
+		assert.Equal(t, initialReviewerID, history.Assignments[0].UserID, "Initial reviewer should be first")
+		assert.Equal(t, model.AssignmentReasonInitial, history.Assignments[0].Reason, "Initial reason should match")
+		require.NotNil(t, history.Assignments[0].UnassignedAt, "Initial reviewer should be unassigned")
+		assert.Equal(t, model.AssignmentReasonReassign, history.Assignments[0].UnassignReason, "Unassign reason should match")
+
+		assert.Equal(t, reassignedReviewerID, history.Assignments[1].UserID, "Reassigned reviewer should be second")
+		assert.Equal(t, model.AssignmentReasonReassign, history.Assignments[1].Reason, "Reassign reason should match")
+		require.NotNil(t, history.Assignments[1].UnassignedAt, "Deactivated reviewer should be unassigned")
+		assert.Equal(t, model.AssignmentReasonDeactivation, history.Assignments[1].UnassignReason, "Unassign reason should match")
+
+		assert.Equal(t, finalReviewerID, history.Assignments[2].UserID, "Replacement should be last")
+		assert.Equal(t, model.AssignmentReasonDeactivation, history.Assignments[2].Reason, "Replacement reason should match")
+		assert.Nil(t, history.Assignments[2].UnassignedAt, "Current reviewer should stay assigned")
+	})
+
+	t.Run("Timeline is chronological", func(t *testing.T) {
+		resp, statusCode, err := client.GetPRHistory(prID)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Getting history should succeed")
+		history := resp.(model.PRHistory)
+
+		expected := []model.PRHistoryEvent{
+			{Type: model.PRHistoryStatusChanged, ToStatus: model.PRStatusOpen},
+			{Type: model.PRHistoryReviewerAssigned, ReviewerID: initialReviewerID, Reason: model.AssignmentReasonInitial},
+			{Type: model.PRHistoryReviewerUnassigned, ReviewerID: initialReviewerID, Reason: model.AssignmentReasonReassign},
+			{Type: model.PRHistoryReviewerAssigned, ReviewerID: reassignedReviewerID, Reason: model.AssignmentReasonReassign},
+			{Type: model.PRHistoryReviewerUnassigned, ReviewerID: reassignedReviewerID, Reason: model.AssignmentReasonDeactivation},
+			{Type: model.PRHistoryReviewerAssigned, ReviewerID: finalReviewerID, Reason: model.AssignmentReasonDeactivation},
+			{Type: model.PRHistoryStatusChanged, FromStatus: model.PRStatusOpen, ToStatus: model.PRStatusMerged},
+		}
+		require.Len(t, history.Timeline, len(expected), "Every change should be in the timeline")
+		for i, event := range history.Timeline {
+			if i > 0 {
+				assert.False(t, event.At.Before(history.Timeline[i-1].At), "Event %d should not precede the previous one", i)
+			}
+			event.At = time.Time{}
+			assert.Equal(t, expected[i], event, "Event %d should match", i)
+		}
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		_, statusCode, err := client.GetPRHistory(fmt.Sprintf("history-missing-%d", timestamp))
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusNotFound, statusCode, "Unknown PR should not be found")
+
+		_, statusCode, err = client.GetPRHistory("")
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusBadRequest, statusCode, "Empty PR ID should be rejected")
+	})
+}
+
+func TestListPRs(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("list-test-team-%d", timestamp)
+	authorID := fmt.Sprintf("list-author-%d", timestamp)
+	reviewerID := fmt.Sprintf("list-reviewer-%d", timestamp)
+
+	team := &model.Team{
+		TeamName:          teamName,
+		ReviewersRequired: 1,
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "List Author", IsActive: true},
+			{UserID: reviewerID, Username: "List Reviewer", IsActive: true},
+		},
+	}
+
+	_, statusCode, err := client.AddTeam(team)
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	prIDs := []string{}
+	for i := range 5 {
+		prID := fmt.Sprintf("list-pr-%d-%d", timestamp, i)
+		_, statusCode, err = client.CreatePR(prID, "List PR", authorID)
+		require.NoError(t, err, "Creating PR should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+		prIDs = append(prIDs, prID)
+	}
+
+	_, statusCode, err = client.MergePR(prIDs[0])
+	require.NoError(t, err, "Merging PR should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "PR merge should succeed")
+
+	t.Run("Paginate by team", func(t *testing.T) {
+		listed := []string{}
+		cursor := ""
+		for page := 0; ; page++ {
+			require.Less(t, page, len(prIDs), "Pagination should terminate")
+
+			params := url.Values{}
+			params.Add("team_name", teamName)
+			params.Add("limit", "2")
+			if cursor != "" {
+				params.Add("cursor", cursor)
+			}
+
+			resp, statusCode, err := client.ListPRs(params)
+			require.NoError(t, err, "API call should not fail")
+			require.Equal(t, http.StatusOK, statusCode, "Listing should succeed")
+
+			respData := resp.(map[string]interface{})
+			prs, ok := respData["pull_requests"].([]interface{})
+			require.True(t, ok, "Response should have 'pull_requests' field")
+			assert.LessOrEqual(t, len(prs), 2, "Page should respect the limit")
+			for _, pr := range prs {
+				listed = append(listed, pr.(map[string]interface{})["pull_request_id"].(string))
+			}
+
+			cursor, _ = respData["next_cursor"].(string)
+			if cursor == "" {
+				break
+			}
+		}
+
+		expected := slices.Clone(prIDs)
+		slices.Reverse(expected)
+		assert.Equal(t, expected, listed, "All PRs should be listed from the newest to the oldest")
+	})
+
+	filterCases := []struct {
+		name     string
+		params   url.Values
+		expected []string
+	}{
+		{
+			name:     "Filter by status",
+			params:   url.Values{"author_id": {authorID}, "status": {model.PRStatusMerged}},
+			expected: []string{prIDs[0]},
+		},
+		{
+			name:     "Filter by reviewer",
+			params:   url.Values{"reviewer_id": {reviewerID}, "status": {model.PRStatusOpen}},
+			expected: prIDs[1:],
+		},
+		{
+			name:     "Filter by merge time",
+			params:   url.Values{"author_id": {authorID}, "merged_from": {time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)}},
+			expected: []string{prIDs[0]},
+		},
+		{
+			name:     "Filter by creation time",
+			params:   url.Values{"author_id": {authorID}, "created_to": {time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)}},
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range filterCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, statusCode, err := client.ListPRs(tc.params)
+			require.NoError(t, err, "API call should not fail")
+			require.Equal(t, http.StatusOK, statusCode, "Listing should succeed")
+
+			prs, ok := resp.(map[string]interface{})["pull_requests"].([]interface{})
+			require.True(t, ok, "Response should have 'pull_requests' field")
+			listed := []string{}
+			for _, pr := range prs {
+				listed = append(listed, pr.(map[string]interface{})["pull_request_id"].(string))
+			}
+			assert.ElementsMatch(t, tc.expected, listed, "Listed PRs should match expected")
+		})
+	}
+
+	invalidCases := []struct {
+		name   string
+		params url.Values
+	}{
+		{name: "Unknown status", params: url.Values{"status": {"UNKNOWN"}}},
+		{name: "Invalid limit", params: url.Values{"limit": {"0"}}},
+		{name: "Invalid time", params: url.Values{"created_from": {"yesterday"}}},
+		{name: "Invalid cursor", params: url.Values{"cursor": {"not-a-cursor"}}},
+	}
+
+	for _, tc := range invalidCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, statusCode, err := client.ListPRs(tc.params)
+			require.NoError(t, err, "API call should not fail")
+			assert.Equal(t, http.StatusBadRequest, statusCode, "Status code should match expected")
+
+			errorMap, ok := resp.(map[string]interface{})["error"].(map[string]interface{})
+			require.True(t, ok, "Error response should have 'error' field")
+			assert.Equal(t, model.CodeInvalidField, errorMap["code"], "Error code should match expected")
+		})
+	}
+}
+
+func responseReviewerIDs(reviewers []interface{}) []string {
+	ids := make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		if reviewerMap, ok := reviewer.(map[string]interface{}); ok {
+			if id, ok := reviewerMap["user_id"].(string); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+func TestReviewerCapacity(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("capacity-team-%d", timestamp)
+	authorID := fmt.Sprintf("capacity-author-%d", timestamp)
+	limitedID := fmt.Sprintf("capacity-limited-%d", timestamp)
+	busyID := fmt.Sprintf("capacity-busy-%d", timestamp)
+	oneReview, noReviews := 1, 0
+
+	team := &model.Team{
+		TeamName:          teamName,
+		ReviewersRequired: 2,
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "Capacity Author", IsActive: true},
+			{UserID: limitedID, Username: "Capacity Limited", IsActive: true, MaxOpenReviews: &oneReview},
+			{UserID: busyID, Username: "Capacity Busy", IsActive: true, MaxOpenReviews: &noReviews},
+		},
+	}
+
+	_, statusCode, err := client.AddTeam(team)
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	t.Run("Team shows capacity", func(t *testing.T) {
+		resp, statusCode, err := client.GetTeam(teamName)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Getting team should succeed")
+
+		for _, member := range resp.(model.Team).Members {
+			if member.UserID == limitedID {
+				require.NotNil(t, member.MaxOpenReviews, "Capacity should be returned")
+				assert.Equal(t, 1, *member.MaxOpenReviews, "Capacity should match")
+			}
+		}
+	})
+
+	t.Run("Reviewers at capacity are skipped", func(t *testing.T) {
+		for i, expected := range [][]string{{limitedID}, {}} {
+			resp, statusCode, err := client.CreatePR(fmt.Sprintf("capacity-pr-%d-%d", i, timestamp), "Capacity PR", authorID)
+			require.NoError(t, err, "Creating PR should not fail")
+			require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+			prMap, ok := resp.(map[string]interface{})["pr"].(map[string]interface{})
+			require.True(t, ok, "Response should have 'pr' field")
+			reviewers, ok := prMap["assigned_reviewers"].([]interface{})
+			require.True(t, ok, "PR should have assigned reviewers")
+
+			assigned := []string{}
+			for _, reviewer := range reviewers {
+				assigned = append(assigned, reviewer.(map[string]interface{})["user_id"].(string))
+			}
+			assert.ElementsMatch(t, expected, assigned, "Only reviewers below capacity should be assigned")
+			assert.Equal(t, true, prMap["understaffed"], "PR should be flagged as understaffed")
+		}
+	})
+}
+
+func TestLeadReview(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("lead-team-%d", timestamp)
+	authorID := fmt.Sprintf("lead-author-%d", timestamp)
+	leadIDs := []string{fmt.Sprintf("lead-first-%d", timestamp), fmt.Sprintf("lead-second-%d", timestamp)}
+	prID := fmt.Sprintf("lead-pr-%d", timestamp)
+
+	team := &model.Team{
+		TeamName:          teamName,
+		ReviewersRequired: 1,
+		RequireLeadReview: true,
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "Lead Author", IsActive: true},
+			{UserID: leadIDs[0], Username: "Lead First", IsActive: true, Role: model.RoleLead},
+			{UserID: leadIDs[1], Username: "Lead Second", IsActive: true, Role: model.RoleLead},
+			{UserID: fmt.Sprintf("lead-member-first-%d", timestamp), Username: "Lead Member First", IsActive: true},
+			{UserID: fmt.Sprintf("lead-member-second-%d", timestamp), Username: "Lead Member Second", IsActive: true},
+		},
+	}
+
+	_, statusCode, err := client.AddTeam(team)
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("Roles are stored", func(t *testing.T) {
+		dbTeam, err := dbVerifier.GetTeam(ctx, teamName)
+		require.NoError(t, err, "Getting team from database should not fail")
+		for _, member := range dbTeam.Members {
+			expectedRole := model.RoleMember
+			if slices.Contains(leadIDs, member.UserID) {
+				expectedRole = model.RoleLead
+			}
+			assert.Equal(t, expectedRole, member.Role, "Role of %s should match", member.UserID)
+		}
+	})
+
+	t.Run("Invalid role", func(t *testing.T) {
+		_, statusCode, err := client.AddMembers(teamName, []model.TeamMember{
+			{UserID: fmt.Sprintf("lead-invalid-%d", timestamp), Username: "Lead Invalid", IsActive: true, Role: "owner"},
+		})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusBadRequest, statusCode, "Unknown role should be rejected")
+	})
+
+	var leadReviewerID string
+	t.Run("Slot is reserved for a lead", func(t *testing.T) {
+		_, statusCode, err := client.CreatePR(prID, "Lead PR", authorID)
+		require.NoError(t, err, "Creating PR should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+		dbPR, err := dbVerifier.GetPullRequest(ctx, prID)
+		require.NoError(t, err, "Getting PR from database should not fail")
+		require.Len(t, dbPR.AssignedReviewers, 1, "One reviewer should be assigned")
+		leadReviewerID = dbPR.AssignedReviewers[0].UserID
+		assert.Contains(t, leadIDs, leadReviewerID, "Reviewer should be a lead")
+	})
+
+	t.Run("Reassignment keeps a lead", func(t *testing.T) {
+		require.NotEmpty(t, leadReviewerID, "Lead reviewer should be assigned")
+		resp, statusCode, err := client.ReassignPR(prID, leadReviewerID)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "PR reassignment should succeed")
+
+		replacedBy := resp.(map[string]interface{})["replaced_by"]
+		assert.Contains(t, leadIDs, replacedBy, "Replacement should be a lead")
+		assert.NotEqual(t, leadReviewerID, replacedBy, "Replacement should be another lead")
+	})
+}
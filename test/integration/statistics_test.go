@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
 	"testing"
 	"time"
 
@@ -58,16 +59,10 @@ func TestGetUserStatistics(t *testing.T) {
 		cancel()
 		require.NoError(t, err, "Getting PR from database should not fail")
 
-		userAssigned := false
-		for _, reviewer := range dbPR.AssignedReviewers {
-			if reviewer == userID {
-				userAssigned = true
-				break
-			}
-		}
+		userAssigned := slices.Contains(reviewerIDs(dbPR.AssignedReviewers), userID)
 
 		if !userAssigned && len(dbPR.AssignedReviewers) > 0 {
-			_, statusCode, err = client.ReassignPR(prID, dbPR.AssignedReviewers[0])
+			_, statusCode, err = client.ReassignPR(prID, dbPR.AssignedReviewers[0].UserID)
 			require.NoError(t, err, "Reassigning PR should not fail")
 			require.Equal(t, http.StatusOK, statusCode, "PR reassignment should succeed")
 		}
@@ -106,6 +106,22 @@ func TestAddTeam(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			errorCode:      model.CodeEmptyField,
 		},
+		{
+			name: "Team with unknown assignment strategy",
+			team: &model.Team{
+				TeamName:           fmt.Sprintf("invalid-strategy-team-%d", timestamp),
+				AssignmentStrategy: "alphabetical",
+				Members: []model.TeamMember{
+					{
+						UserID:   fmt.Sprintf("invalid-strategy-user-%d", timestamp),
+						Username: "Frank Backend Developer",
+						IsActive: true,
+					},
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+			errorCode:      model.CodeInvalidField,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -290,6 +306,7 @@ func TestGetTeam(t *testing.T) {
 					assert.True(t, exists, "Member should exist in database")
 					assert.Equal(t, apiMember.Username, dbMember.Username, "Username should match")
 					assert.Equal(t, apiMember.IsActive, dbMember.IsActive, "IsActive should match")
+					assert.Equal(t, apiMember.Role, dbMember.Role, "Role should match")
 				}
 
 			case http.StatusNotFound, http.StatusBadRequest:
@@ -320,3 +337,560 @@ func TestGetTeam(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateTeamSettings(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("settings-team-%d", timestamp)
+	authorID := fmt.Sprintf("settings-author-%d", timestamp)
+	prID := fmt.Sprintf("settings-pr-%d", timestamp)
+
+	members := []model.TeamMember{
+		{UserID: authorID, Username: "Settings Author", IsActive: true},
+	}
+	for i := 1; i <= 4; i++ {
+		members = append(members, model.TeamMember{
+			UserID:   fmt.Sprintf("settings-reviewer-%d-%d", timestamp, i),
+			Username: fmt.Sprintf("Settings Reviewer %d", i),
+			IsActive: true,
+		})
+	}
+
+	_, statusCode, err := client.AddTeam(&model.Team{TeamName: teamName, ReviewersRequired: 3, Members: members})
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	_, statusCode, err = client.CreatePR(prID, "Settings PR", authorID)
+	require.NoError(t, err, "Creating PR should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	dbPR, err := dbVerifier.GetPullRequest(ctx, prID)
+	require.NoError(t, err, "Getting PR from database should not fail")
+	require.Len(t, dbPR.AssignedReviewers, 3, "PR should get the team's required number of reviewers")
+
+	testCases := []struct {
+		name           string
+		settings       *model.TeamSettings
+		expectedStatus int
+		errorCode      string
+	}{
+		{
+			name:           "Decrease reviewers required",
+			settings:       &model.TeamSettings{TeamName: teamName, ReviewersRequired: 1},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Unknown strategy",
+			settings:       &model.TeamSettings{TeamName: teamName, AssignmentStrategy: "alphabetical"},
+			expectedStatus: http.StatusBadRequest,
+			errorCode:      model.CodeInvalidField,
+		},
+		{
+			name:           "Non-existent team",
+			settings:       &model.TeamSettings{TeamName: "non-existent-team", ReviewersRequired: 1},
+			expectedStatus: http.StatusNotFound,
+			errorCode:      model.CodeNotFound,
+		},
+		{
+			name:           "Empty team name",
+			settings:       &model.TeamSettings{ReviewersRequired: 1},
+			expectedStatus: http.StatusBadRequest,
+			errorCode:      model.CodeEmptyField,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, statusCode, err := client.UpdateTeamSettings(tc.settings)
+			require.NoError(t, err, "API call should not fail")
+			assert.Equal(t, tc.expectedStatus, statusCode, "Status code should match expected")
+
+			respData, ok := resp.(map[string]interface{})
+			require.True(t, ok, "Response should be a map")
+
+			switch statusCode {
+			case http.StatusOK:
+				settings, ok := respData["settings"].(map[string]interface{})
+				require.True(t, ok, "Response should have 'settings' field")
+				assert.Equal(t, float64(tc.settings.ReviewersRequired), settings["reviewers_required"], "Reviewers required should match")
+				assert.Equal(t, model.StrategyLeastLoaded, settings["assignment_strategy"], "Strategy should stay unchanged")
+
+			default:
+				errorMap, ok := respData["error"].(map[string]interface{})
+				require.True(t, ok, "Error response should have 'error' field")
+				assert.Equal(t, tc.errorCode, errorMap["code"], "Error code should match expected")
+			}
+		})
+	}
+
+	resp, statusCode, err := client.ReassignPR(prID, dbPR.AssignedReviewers[0].UserID)
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "PR reassignment should succeed")
+
+	respData, ok := resp.(map[string]interface{})
+	require.True(t, ok, "Response should be a map")
+	assert.Empty(t, respData["replaced_by"], "Reviewer of an overstaffed PR should not be replaced")
+
+	dbPR, err = dbVerifier.GetPullRequest(ctx, prID)
+	require.NoError(t, err, "Getting PR from database should not fail")
+	assert.Len(t, dbPR.AssignedReviewers, 2, "Overstaffed PR should lose the reviewer")
+}
+
+func TestTeamMembership(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("membership-team-%d", timestamp)
+	renamedTeamName := fmt.Sprintf("membership-renamed-team-%d", timestamp)
+	authorID := fmt.Sprintf("membership-author-%d", timestamp)
+	reviewerID := fmt.Sprintf("membership-reviewer-%d", timestamp)
+	newMemberID := fmt.Sprintf("membership-new-%d", timestamp)
+	prID := fmt.Sprintf("membership-pr-%d", timestamp)
+
+	team := &model.Team{
+		TeamName:          teamName,
+		ReviewersRequired: 1,
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "Membership Author", IsActive: true},
+			{UserID: reviewerID, Username: "Membership Reviewer", IsActive: true},
+		},
+	}
+
+	_, statusCode, err := client.AddTeam(team)
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	_, statusCode, err = client.CreatePR(prID, "Membership PR", authorID)
+	require.NoError(t, err, "Creating PR should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("Add members", func(t *testing.T) {
+		resp, statusCode, err := client.AddMembers(teamName, []model.TeamMember{
+			{UserID: newMemberID, Username: "Membership New", IsActive: true},
+		})
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Adding members should succeed")
+
+		teamMap, ok := resp.(map[string]interface{})["team"].(map[string]interface{})
+		require.True(t, ok, "Response should have 'team' field")
+		members, ok := teamMap["members"].([]interface{})
+		require.True(t, ok, "Team should have members")
+		assert.Len(t, members, 3, "Team should have three members")
+
+		_, statusCode, err = client.AddMembers("non-existent-team", []model.TeamMember{
+			{UserID: fmt.Sprintf("membership-other-%d", timestamp), Username: "Other", IsActive: true},
+		})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusNotFound, statusCode, "Adding members to unknown team should fail")
+	})
+
+	t.Run("Remove member reassigns open reviews", func(t *testing.T) {
+		resp, statusCode, err := client.RemoveMember(teamName, reviewerID)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Removing member should succeed")
+
+		reassigned, ok := resp.(map[string]interface{})["reassigned"].([]interface{})
+		require.True(t, ok, "Response should have 'reassigned' field")
+		require.Len(t, reassigned, 1, "One open review should be handed over")
+		handover := reassigned[0].(map[string]interface{})
+		assert.Equal(t, prID, handover["pull_request_id"], "Handover PR should match")
+		assert.Equal(t, newMemberID, handover["new_reviewer_id"], "Review should go to the remaining member")
+
+		dbPR, err := dbVerifier.GetPullRequest(ctx, prID)
+		require.NoError(t, err, "Getting PR from database should not fail")
+		assert.Equal(t, []string{newMemberID}, reviewerIDs(dbPR.AssignedReviewers), "Reviewers in database should be updated")
+
+		user, err := dbVerifier.GetUser(ctx, reviewerID)
+		require.NoError(t, err, "Getting user from database should not fail")
+		assert.Empty(t, user.TeamName, "Removed user should have no team")
+
+		_, statusCode, err = client.RemoveMember(teamName, reviewerID)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusNotFound, statusCode, "Removing non-member should fail")
+	})
+
+	t.Run("Rename team", func(t *testing.T) {
+		resp, statusCode, err := client.RenameTeam(teamName, renamedTeamName)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Renaming team should succeed")
+
+		teamMap, ok := resp.(map[string]interface{})["team"].(map[string]interface{})
+		require.True(t, ok, "Response should have 'team' field")
+		assert.Equal(t, renamedTeamName, teamMap["team_name"], "Team name should be updated")
+
+		user, err := dbVerifier.GetUser(ctx, authorID)
+		require.NoError(t, err, "Getting user from database should not fail")
+		assert.Equal(t, renamedTeamName, user.TeamName, "Members should follow the renamed team")
+
+		_, statusCode, err = client.GetTeam(teamName)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusNotFound, statusCode, "Old team name should not exist")
+	})
+
+	t.Run("Delete team with open PRs", func(t *testing.T) {
+		resp, statusCode, err := client.DeleteTeam(renamedTeamName, false)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusConflict, statusCode, "Deleting team with open PRs should fail")
+		errorMap, ok := resp.(map[string]interface{})["error"].(map[string]interface{})
+		require.True(t, ok, "Error response should have 'error' field")
+		assert.Equal(t, model.CodeTeamHasOpenPRs, errorMap["code"], "Error code should match expected")
+
+		_, statusCode, err = client.DeleteTeam(renamedTeamName, true)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Forced deletion should succeed")
+
+		exists, err := dbVerifier.VerifyTeamExists(ctx, renamedTeamName)
+		require.NoError(t, err, "Verifying team should not fail")
+		assert.False(t, exists, "Team should be deleted")
+
+		dbPR, err := dbVerifier.GetPullRequest(ctx, prID)
+		require.NoError(t, err, "Getting PR from database should not fail")
+		assert.Empty(t, dbPR.AssignedReviewers, "Reviews of deleted team members without candidates should be dropped")
+	})
+}
+
+func TestDeactivateUsers(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("bulk-team-%d", timestamp)
+	authorID := fmt.Sprintf("bulk-author-%d", timestamp)
+	memberIDs := []string{
+		fmt.Sprintf("bulk-member-1-%d", timestamp),
+		fmt.Sprintf("bulk-member-2-%d", timestamp),
+		fmt.Sprintf("bulk-member-3-%d", timestamp),
+		fmt.Sprintf("bulk-member-4-%d", timestamp),
+	}
+	deactivatedIDs := memberIDs[:2]
+
+	team := &model.Team{
+		TeamName:          teamName,
+		ReviewersRequired: 2,
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "Bulk Author", IsActive: true},
+			{UserID: memberIDs[0], Username: "Bulk Member 1", IsActive: true},
+			{UserID: memberIDs[1], Username: "Bulk Member 2", IsActive: true},
+			{UserID: memberIDs[2], Username: "Bulk Member 3", IsActive: true},
+			{UserID: memberIDs[3], Username: "Bulk Member 4", IsActive: true},
+		},
+	}
+
+	_, statusCode, err := client.AddTeam(team)
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	prIDs := []string{}
+	for i := range 5 {
+		prID := fmt.Sprintf("bulk-pr-%d-%d", i, timestamp)
+		_, statusCode, err = client.CreatePR(prID, "Bulk PR", authorID)
+		require.NoError(t, err, "Creating PR should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+		prIDs = append(prIDs, prID)
+	}
+	memberPRID := fmt.Sprintf("bulk-member-pr-%d", timestamp)
+	_, statusCode, err = client.CreatePR(memberPRID, "Bulk Member PR", memberIDs[2])
+	require.NoError(t, err, "Creating PR should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+	prIDs = append(prIDs, memberPRID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("Unknown member", func(t *testing.T) {
+		resp, statusCode, err := client.DeactivateUsers(teamName, []string{memberIDs[0], "non-existent-user"})
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusNotFound, statusCode, "Deactivating unknown member should fail")
+		errorMap, ok := resp.(map[string]interface{})["error"].(map[string]interface{})
+		require.True(t, ok, "Error response should have 'error' field")
+		assert.Equal(t, model.CodeNotFound, errorMap["code"], "Error code should match expected")
+
+		user, err := dbVerifier.GetUser(ctx, memberIDs[0])
+		require.NoError(t, err, "Getting user from database should not fail")
+		assert.True(t, user.IsActive, "Failed request should not deactivate anyone")
+	})
+
+	t.Run("Empty user list", func(t *testing.T) {
+		_, statusCode, err := client.DeactivateUsers(teamName, []string{})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusBadRequest, statusCode, "Empty user list should fail")
+	})
+
+	t.Run("Deactivate and reassign", func(t *testing.T) {
+		resp, statusCode, err := client.DeactivateUsers(teamName, deactivatedIDs)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Bulk deactivation should succeed")
+
+		reassigned, ok := resp.(map[string]interface{})["reassigned"].([]interface{})
+		require.True(t, ok, "Response should have 'reassigned' field")
+		for _, item := range reassigned {
+			reassignment := item.(map[string]interface{})
+			assert.Contains(t, prIDs, reassignment["pull_request_id"], "Only team PRs should be affected")
+			assert.NotEmpty(t, reassignment["removed_reviewers"], "Removed reviewers should be listed")
+			assert.NotEmpty(t, reassignment["outcome"], "Outcome should be set")
+		}
+
+		for _, userID := range deactivatedIDs {
+			user, err := dbVerifier.GetUser(ctx, userID)
+			require.NoError(t, err, "Getting user from database should not fail")
+			assert.False(t, user.IsActive, "User should be deactivated")
+		}
+
+		for _, prID := range prIDs {
+			dbPR, err := dbVerifier.GetPullRequest(ctx, prID)
+			require.NoError(t, err, "Getting PR from database should not fail")
+			reviewers := reviewerIDs(dbPR.AssignedReviewers)
+			assert.LessOrEqual(t, len(reviewers), 2, "PR should not exceed its reviewer limit")
+			assert.NotContains(t, reviewers, dbPR.AuthorID, "Author should not review own PR")
+			for _, userID := range deactivatedIDs {
+				assert.NotContains(t, reviewers, userID, "Deactivated users should not review")
+			}
+		}
+
+		dbPR, err := dbVerifier.GetPullRequest(ctx, prIDs[0])
+		require.NoError(t, err, "Getting PR from database should not fail")
+		assert.ElementsMatch(t, memberIDs[2:], reviewerIDs(dbPR.AssignedReviewers), "Remaining members should take over")
+	})
+}
+
+func TestMultipleTeamMembership(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	firstTeamName := fmt.Sprintf("multi-first-team-%d", timestamp)
+	secondTeamName := fmt.Sprintf("multi-second-team-%d", timestamp)
+	platformID := fmt.Sprintf("multi-platform-%d", timestamp)
+	firstMemberID := fmt.Sprintf("multi-first-member-%d", timestamp)
+	secondMemberID := fmt.Sprintf("multi-second-member-%d", timestamp)
+
+	teams := []*model.Team{
+		{
+			TeamName:          firstTeamName,
+			ReviewersRequired: 1,
+			Members: []model.TeamMember{
+				{UserID: platformID, Username: "Multi Platform", IsActive: true},
+				{UserID: firstMemberID, Username: "Multi First Member", IsActive: true},
+			},
+		},
+		{
+			TeamName:          secondTeamName,
+			ReviewersRequired: 1,
+			Members: []model.TeamMember{
+				{UserID: platformID, Username: "Multi Platform", IsActive: true},
+				{UserID: secondMemberID, Username: "Multi Second Member", IsActive: true},
+			},
+		},
+	}
+	for _, team := range teams {
+		_, statusCode, err := client.AddTeam(team)
+		require.NoError(t, err, "Adding team should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("User belongs to both teams", func(t *testing.T) {
+		for _, teamName := range []string{firstTeamName, secondTeamName} {
+			dbTeam, err := dbVerifier.GetTeam(ctx, teamName)
+			require.NoError(t, err, "Getting team from database should not fail")
+			memberIDs := []string{}
+			for _, member := range dbTeam.Members {
+				memberIDs = append(memberIDs, member.UserID)
+			}
+			assert.Contains(t, memberIDs, platformID, "User should be a member of every team")
+		}
+
+		user, err := dbVerifier.GetUser(ctx, platformID)
+		require.NoError(t, err, "Getting user from database should not fail")
+		assert.ElementsMatch(t, []string{firstTeamName, secondTeamName}, user.Teams, "User should have both teams")
+	})
+
+	t.Run("PR team is required for several teams", func(t *testing.T) {
+		resp, statusCode, err := client.CreatePR(fmt.Sprintf("multi-pr-no-team-%d", timestamp), "Multi PR", platformID)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusBadRequest, statusCode, "PR without team should fail")
+		errorMap, ok := resp.(map[string]interface{})["error"].(map[string]interface{})
+		require.True(t, ok, "Error response should have 'error' field")
+		assert.Equal(t, model.CodeEmptyField, errorMap["code"], "Error code should match expected")
+
+		_, statusCode, err = client.CreatePRInTeam(fmt.Sprintf("multi-pr-foreign-%d", timestamp), "Multi PR", firstMemberID, secondTeamName)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusBadRequest, statusCode, "PR in a team of which the author is not a member should fail")
+	})
+
+	t.Run("Reviewers come from the PR team", func(t *testing.T) {
+		for teamName, expectedReviewer := range map[string]string{
+			firstTeamName:  firstMemberID,
+			secondTeamName: secondMemberID,
+		} {
+			prID := fmt.Sprintf("multi-pr-%s", teamName)
+			resp, statusCode, err := client.CreatePRInTeam(prID, "Multi PR", platformID, teamName)
+			require.NoError(t, err, "Creating PR should not fail")
+			require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+			prMap, ok := resp.(map[string]interface{})["pr"].(map[string]interface{})
+			require.True(t, ok, "Response should have 'pr' field")
+			assert.Equal(t, teamName, prMap["team_name"], "PR team should match")
+
+			dbPR, err := dbVerifier.GetPullRequest(ctx, prID)
+			require.NoError(t, err, "Getting PR from database should not fail")
+			assert.Equal(t, []string{expectedReviewer}, reviewerIDs(dbPR.AssignedReviewers), "Reviewer should be from the PR team")
+		}
+	})
+
+	t.Run("Removing from one team keeps the other", func(t *testing.T) {
+		_, statusCode, err := client.RemoveMember(firstTeamName, platformID)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Removing member should succeed")
+
+		user, err := dbVerifier.GetUser(ctx, platformID)
+		require.NoError(t, err, "Getting user from database should not fail")
+		assert.Equal(t, []string{secondTeamName}, user.Teams, "User should stay in the other team")
+		assert.Equal(t, secondTeamName, user.TeamName, "Single team should be reported as team_name")
+	})
+}
+
+func TestTeamHierarchy(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	parentTeamName := fmt.Sprintf("hierarchy-parent-%d", timestamp)
+	childTeamName := fmt.Sprintf("hierarchy-child-%d", timestamp)
+	grandchildTeamName := fmt.Sprintf("hierarchy-grandchild-%d", timestamp)
+	parentReviewerID := fmt.Sprintf("hierarchy-parent-reviewer-%d", timestamp)
+	childAuthorID := fmt.Sprintf("hierarchy-child-author-%d", timestamp)
+	grandchildAuthorID := fmt.Sprintf("hierarchy-grandchild-author-%d", timestamp)
+
+	teams := []*model.Team{
+		{
+			TeamName:          parentTeamName,
+			ReviewersRequired: 1,
+			Members: []model.TeamMember{
+				{UserID: parentReviewerID, Username: "Hierarchy Parent Reviewer", IsActive: true},
+			},
+		},
+		{
+			TeamName:          childTeamName,
+			ReviewersRequired: 1,
+			ParentTeamName:    parentTeamName,
+			Members: []model.TeamMember{
+				{UserID: childAuthorID, Username: "Hierarchy Child Author", IsActive: true},
+			},
+		},
+		{
+			TeamName:          grandchildTeamName,
+			ReviewersRequired: 1,
+			ParentTeamName:    childTeamName,
+			Members: []model.TeamMember{
+				{UserID: grandchildAuthorID, Username: "Hierarchy Grandchild Author", IsActive: true},
+			},
+		},
+	}
+	for _, team := range teams {
+		_, statusCode, err := client.AddTeam(team)
+		require.NoError(t, err, "Adding team should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("Parent team is stored", func(t *testing.T) {
+		dbTeam, err := dbVerifier.GetTeam(ctx, childTeamName)
+		require.NoError(t, err, "Getting team from database should not fail")
+		assert.Equal(t, parentTeamName, dbTeam.ParentTeamName, "Parent team should match")
+	})
+
+	t.Run("Unknown parent team", func(t *testing.T) {
+		_, statusCode, err := client.AddTeam(&model.Team{
+			TeamName:       fmt.Sprintf("hierarchy-orphan-%d", timestamp),
+			ParentTeamName: fmt.Sprintf("hierarchy-missing-%d", timestamp),
+			Members: []model.TeamMember{
+				{UserID: fmt.Sprintf("hierarchy-orphan-member-%d", timestamp), Username: "Hierarchy Orphan", IsActive: true},
+			},
+		})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusNotFound, statusCode, "Team with unknown parent should not be created")
+	})
+
+	t.Run("Get returns the subtree", func(t *testing.T) {
+		resp, statusCode, err := client.GetTeam(parentTeamName)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Getting team should succeed")
+		assert.Empty(t, resp.(model.Team).SubTeams, "Sub-teams should be returned only on request")
+
+		resp, statusCode, err = client.GetTeamWithSubTeams(parentTeamName)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Getting team should succeed")
+
+		team := resp.(model.Team)
+		require.Len(t, team.SubTeams, 1, "Parent should have one sub-team")
+		assert.Equal(t, childTeamName, team.SubTeams[0].TeamName, "Sub-team should match")
+		assert.Equal(t, parentTeamName, team.SubTeams[0].ParentTeamName, "Sub-team parent should match")
+		require.Len(t, team.SubTeams[0].SubTeams, 1, "Child should have one sub-team")
+		assert.Equal(t, grandchildTeamName, team.SubTeams[0].SubTeams[0].TeamName, "Nested sub-team should match")
+	})
+
+	t.Run("Cycles are rejected", func(t *testing.T) {
+		resp, statusCode, err := client.UpdateTeamSettings(&model.TeamSettings{
+			TeamName:       parentTeamName,
+			ParentTeamName: &grandchildTeamName,
+		})
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusBadRequest, statusCode, "Moving a team under its descendant should fail")
+		errorMap, ok := resp.(map[string]interface{})["error"].(map[string]interface{})
+		require.True(t, ok, "Error response should have 'error' field")
+		assert.Equal(t, model.CodeInvalidField, errorMap["code"], "Error code should match expected")
+	})
+
+	t.Run("Reviewers are escalated to ancestor teams", func(t *testing.T) {
+		for _, authorID := range []string{childAuthorID, grandchildAuthorID} {
+			prID := fmt.Sprintf("hierarchy-pr-%s", authorID)
+			_, statusCode, err := client.CreatePR(prID, "Hierarchy PR", authorID)
+			require.NoError(t, err, "Creating PR should not fail")
+			require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+			dbPR, err := dbVerifier.GetPullRequest(ctx, prID)
+			require.NoError(t, err, "Getting PR from database should not fail")
+			require.Len(t, dbPR.AssignedReviewers, 1, "One reviewer should be assigned")
+			if authorID == grandchildAuthorID {
+				assert.Equal(t, childAuthorID, dbPR.AssignedReviewers[0].UserID, "Reviewer should come from the nearest ancestor")
+			} else {
+				assert.Equal(t, parentReviewerID, dbPR.AssignedReviewers[0].UserID, "Reviewer should come from the parent team")
+			}
+			assert.True(t, dbPR.AssignedReviewers[0].IsFallback, "Escalated reviewer should be marked as fallback")
+		}
+	})
+
+	t.Run("Statistics include sub-teams", func(t *testing.T) {
+		resp, statusCode, err := client.GetTeamStatistics(parentTeamName)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Getting statistics should succeed")
+
+		stats := resp.(model.TeamStatistics)
+		assert.Equal(t, []string{childTeamName, grandchildTeamName}, stats.SubTeams, "Sub-teams should be listed")
+		assert.Equal(t, 2, stats.TotalPRs, "PRs of sub-teams should be counted")
+		assert.Equal(t, 2, stats.OpenPRs, "Open PRs of sub-teams should be counted")
+
+		dbStats, err := dbVerifier.GetTeamStatistics(ctx, parentTeamName)
+		require.NoError(t, err, "Getting team statistics from database should not fail")
+		assert.Equal(t, dbStats.TotalPRs, stats.TotalPRs, "Total PRs in database should match")
+	})
+}
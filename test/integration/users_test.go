@@ -263,3 +263,292 @@ func TestGetUserReview(t *testing.T) {
 		})
 	}
 }
+
+func TestMoveUser(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	oldTeamName := fmt.Sprintf("move-old-team-%d", timestamp)
+	newTeamName := fmt.Sprintf("move-new-team-%d", timestamp)
+	authorID := fmt.Sprintf("move-author-%d", timestamp)
+	firstReviewerID := fmt.Sprintf("move-reviewer-1-%d", timestamp)
+	secondReviewerID := fmt.Sprintf("move-reviewer-2-%d", timestamp)
+	prID := fmt.Sprintf("move-pr-%d", timestamp)
+
+	teams := []*model.Team{
+		{
+			TeamName:          oldTeamName,
+			ReviewersRequired: 1,
+			Members: []model.TeamMember{
+				{UserID: authorID, Username: "Move Author", IsActive: true},
+				{UserID: firstReviewerID, Username: "Move Reviewer 1", IsActive: true},
+				{UserID: secondReviewerID, Username: "Move Reviewer 2", IsActive: true},
+			},
+		},
+		{
+			TeamName: newTeamName,
+			Members: []model.TeamMember{
+				{UserID: fmt.Sprintf("move-new-member-%d", timestamp), Username: "Move New Member", IsActive: true},
+			},
+		},
+	}
+	for _, team := range teams {
+		_, statusCode, err := client.AddTeam(team)
+		require.NoError(t, err, "Adding team should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+	}
+
+	_, statusCode, err := client.CreatePR(prID, "Move PR", authorID)
+	require.NoError(t, err, "Creating PR should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dbPR, err := dbVerifier.GetPullRequest(ctx, prID)
+	require.NoError(t, err, "Getting PR from database should not fail")
+	require.Len(t, dbPR.AssignedReviewers, 1, "PR should have one reviewer")
+	movedID := dbPR.AssignedReviewers[0].UserID
+	remainingID := firstReviewerID
+	if movedID == firstReviewerID {
+		remainingID = secondReviewerID
+	}
+
+	t.Run("Move and keep reviews", func(t *testing.T) {
+		resp, statusCode, err := client.MoveUser(movedID, newTeamName, false)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Moving user should succeed")
+
+		respData := resp.(map[string]interface{})
+		userMap, ok := respData["user"].(map[string]interface{})
+		require.True(t, ok, "Response should have 'user' field")
+		assert.Equal(t, newTeamName, userMap["team_name"], "User should be in the new team")
+		assert.Empty(t, respData["reassigned"], "Reviews should be kept")
+
+		dbPR, err := dbVerifier.GetPullRequest(ctx, prID)
+		require.NoError(t, err, "Getting PR from database should not fail")
+		assert.Equal(t, []string{movedID}, reviewerIDs(dbPR.AssignedReviewers), "Reviewer should be kept")
+	})
+
+	t.Run("Move and reassign reviews", func(t *testing.T) {
+		_, statusCode, err := client.MoveUser(movedID, oldTeamName, false)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Moving user back should succeed")
+
+		resp, statusCode, err := client.MoveUser(movedID, newTeamName, true)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Moving user should succeed")
+
+		reassigned, ok := resp.(map[string]interface{})["reassigned"].([]interface{})
+		require.True(t, ok, "Response should have 'reassigned' field")
+		require.Len(t, reassigned, 1, "One review should be handed over")
+		assert.Equal(t, remainingID, reassigned[0].(map[string]interface{})["new_reviewer_id"], "Review should go to the old team")
+
+		dbPR, err := dbVerifier.GetPullRequest(ctx, prID)
+		require.NoError(t, err, "Getting PR from database should not fail")
+		assert.Equal(t, []string{remainingID}, reviewerIDs(dbPR.AssignedReviewers), "Reviewer should be replaced")
+
+		user, err := dbVerifier.GetUser(ctx, movedID)
+		require.NoError(t, err, "Getting user from database should not fail")
+		assert.Equal(t, newTeamName, user.TeamName, "User team in database should be updated")
+	})
+
+	errorCases := []struct {
+		name           string
+		userID         string
+		teamName       string
+		expectedStatus int
+		errorCode      string
+	}{
+		{
+			name:           "Non-existent team",
+			userID:         movedID,
+			teamName:       "non-existent-team",
+			expectedStatus: http.StatusNotFound,
+			errorCode:      model.CodeNotFound,
+		},
+		{
+			name:           "Non-existent user",
+			userID:         "non-existent-user",
+			teamName:       newTeamName,
+			expectedStatus: http.StatusNotFound,
+			errorCode:      model.CodeNotFound,
+		},
+		{
+			name:           "Empty team name",
+			userID:         movedID,
+			expectedStatus: http.StatusBadRequest,
+			errorCode:      model.CodeEmptyField,
+		},
+	}
+
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, statusCode, err := client.MoveUser(tc.userID, tc.teamName, true)
+			require.NoError(t, err, "API call should not fail")
+			assert.Equal(t, tc.expectedStatus, statusCode, "Status code should match expected")
+
+			errorMap, ok := resp.(map[string]interface{})["error"].(map[string]interface{})
+			require.True(t, ok, "Error response should have 'error' field")
+			assert.Equal(t, tc.errorCode, errorMap["code"], "Error code should match expected")
+		})
+	}
+}
+
+func TestDeactivationReassignsReviews(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("deactivation-team-%d", timestamp)
+	authorID := fmt.Sprintf("deactivation-author-%d", timestamp)
+	firstReviewerID := fmt.Sprintf("deactivation-reviewer-1-%d", timestamp)
+	secondReviewerID := fmt.Sprintf("deactivation-reviewer-2-%d", timestamp)
+	prID := fmt.Sprintf("deactivation-pr-%d", timestamp)
+
+	team := &model.Team{
+		TeamName:          teamName,
+		ReviewersRequired: 1,
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "Deactivation Author", IsActive: true},
+			{UserID: firstReviewerID, Username: "Deactivation Reviewer 1", IsActive: true},
+			{UserID: secondReviewerID, Username: "Deactivation Reviewer 2", IsActive: true},
+		},
+	}
+
+	_, statusCode, err := client.AddTeam(team)
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	_, statusCode, err = client.CreatePR(prID, "Deactivation PR", authorID)
+	require.NoError(t, err, "Creating PR should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dbPR, err := dbVerifier.GetPullRequest(ctx, prID)
+	require.NoError(t, err, "Getting PR from database should not fail")
+	require.Len(t, dbPR.AssignedReviewers, 1, "PR should have one reviewer")
+	assignedID := dbPR.AssignedReviewers[0].UserID
+	otherID := firstReviewerID
+	if assignedID == firstReviewerID {
+		otherID = secondReviewerID
+	}
+
+	handovers := func(resp any) []interface{} {
+		reassigned, ok := resp.(map[string]interface{})["reassigned"].([]interface{})
+		require.True(t, ok, "Response should have 'reassigned' field")
+		return reassigned
+	}
+
+	resp, statusCode, err := client.SetUserIsActiveWithReassign(assignedID, false, false)
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "Deactivation should succeed")
+	assert.Empty(t, handovers(resp), "Reviews should be kept when reassignment is disabled")
+
+	dbPR, err = dbVerifier.GetPullRequest(ctx, prID)
+	require.NoError(t, err, "Getting PR from database should not fail")
+	assert.Equal(t, []string{assignedID}, reviewerIDs(dbPR.AssignedReviewers), "Reviewer should be kept")
+
+	_, statusCode, err = client.SetUserIsActive(assignedID, true)
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "Activation should succeed")
+
+	resp, statusCode, err = client.SetUserIsActive(assignedID, false)
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "Deactivation should succeed")
+	reassigned := handovers(resp)
+	require.Len(t, reassigned, 1, "One review should be handed over")
+	handover := reassigned[0].(map[string]interface{})
+	assert.Equal(t, model.HandoverReassigned, handover["outcome"], "Review should be reassigned")
+	assert.Equal(t, otherID, handover["new_reviewer_id"], "Review should go to the other active member")
+
+	resp, statusCode, err = client.SetUserIsActive(otherID, false)
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusOK, statusCode, "Deactivation should succeed")
+	reassigned = handovers(resp)
+	require.Len(t, reassigned, 1, "One review should be reported")
+	handover = reassigned[0].(map[string]interface{})
+	assert.Equal(t, model.HandoverNoCandidate, handover["outcome"], "There should be no candidate left")
+	assert.Empty(t, handover["new_reviewer_id"], "No new reviewer should be assigned")
+
+	dbPR, err = dbVerifier.GetPullRequest(ctx, prID)
+	require.NoError(t, err, "Getting PR from database should not fail")
+	assert.Empty(t, dbPR.AssignedReviewers, "Inactive reviewer should be removed from the PR")
+}
+
+func TestAddAbsence(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("absence-team-%d", timestamp)
+	authorID := fmt.Sprintf("absence-author-%d", timestamp)
+	absentID := fmt.Sprintf("absence-away-%d", timestamp)
+	availableID := fmt.Sprintf("absence-available-%d", timestamp)
+	prID := fmt.Sprintf("absence-pr-%d", timestamp)
+
+	team := &model.Team{
+		TeamName:          teamName,
+		ReviewersRequired: 1,
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "Absence Author", IsActive: true},
+			{UserID: absentID, Username: "Absence Away", IsActive: true},
+			{UserID: availableID, Username: "Absence Available", IsActive: true},
+		},
+	}
+
+	_, statusCode, err := client.AddTeam(team)
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	now := time.Now()
+
+	t.Run("Invalid absences", func(t *testing.T) {
+		_, statusCode, err := client.AddAbsence(absentID, now.Add(time.Hour), now)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusBadRequest, statusCode, "Absence ending before it starts should fail")
+
+		_, statusCode, err = client.AddAbsence("non-existent-user", now, now.Add(time.Hour))
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusNotFound, statusCode, "Absence of unknown user should fail")
+	})
+
+	t.Run("Absent users are not assigned", func(t *testing.T) {
+		_, statusCode, err := client.AddAbsence(absentID, now.Add(-time.Hour), now.Add(time.Hour))
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "Adding current absence should succeed")
+
+		_, statusCode, err = client.AddAbsence(availableID, now.Add(24*time.Hour), now.Add(48*time.Hour))
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "Adding upcoming absence should succeed")
+
+		resp, statusCode, err := client.CreatePR(prID, "Absence PR", authorID)
+		require.NoError(t, err, "Creating PR should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+		prMap, ok := resp.(map[string]interface{})["pr"].(map[string]interface{})
+		require.True(t, ok, "Response should have 'pr' field")
+		reviewers, ok := prMap["assigned_reviewers"].([]interface{})
+		require.True(t, ok, "PR should have assigned reviewers")
+		require.Len(t, reviewers, 1, "One reviewer should be assigned")
+		assert.Equal(t, availableID, reviewers[0].(map[string]interface{})["user_id"], "Absent user should be skipped")
+	})
+
+	t.Run("Team shows absences", func(t *testing.T) {
+		resp, statusCode, err := client.GetTeam(teamName)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Getting team should succeed")
+
+		absences := map[string]int{}
+		for _, member := range resp.(model.Team).Members {
+			absences[member.UserID] = len(member.Absences)
+		}
+		assert.Equal(t, 1, absences[absentID], "Current absence should be listed")
+		assert.Equal(t, 1, absences[availableID], "Upcoming absence should be listed")
+		assert.Equal(t, 0, absences[authorID], "Member without absences should have none")
+	})
+}
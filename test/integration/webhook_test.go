@@ -0,0 +1,368 @@
+package integration
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/karambo3a/avito_test_task/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookReceiver is a subscriber endpoint that records every request. It fails the next
+// failures requests, a negative value fails all of them.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	failures int
+	requests []receivedWebhook
+}
+
+type receivedWebhook struct {
+	header     http.Header
+	body       []byte
+	event      model.WebhookEvent
+	statusCode int
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	var event model.WebhookEvent
+	_ = json.Unmarshal(body, &event)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	statusCode := http.StatusOK
+	if r.failures != 0 {
+		statusCode = http.StatusInternalServerError
+		if r.failures > 0 {
+			r.failures--
+		}
+	}
+	r.requests = append(r.requests, receivedWebhook{header: req.Header.Clone(), body: body, event: event, statusCode: statusCode})
+	w.WriteHeader(statusCode)
+}
+
+func (r *webhookReceiver) setFailures(failures int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = failures
+}
+
+// received returns the requests carrying the event of the given type about the PR.
+func (r *webhookReceiver) received(eventType, prID string) []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var requests []receivedWebhook
+	for _, request := range r.requests {
+		var data struct {
+			PullRequestID string `json:"pull_request_id"`
+		}
+		if request.event.EventType != eventType || json.Unmarshal(request.event.Data, &data) != nil || data.PullRequestID != prID {
+			continue
+		}
+		requests = append(requests, request)
+	}
+	return requests
+}
+
+// startWebhookReceiver listens on all interfaces, so that the service running in a container
+// reaches the receiver through TEST_WEBHOOK_HOST.
+func startWebhookReceiver(t *testing.T) (*webhookReceiver, string) {
+	listener, err := net.Listen("tcp", "0.0.0.0:0")
+	require.NoError(t, err, "Receiver should listen")
+
+	receiver := &webhookReceiver{}
+	server := httptest.NewUnstartedServer(receiver)
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	return receiver, fmt.Sprintf("http://%s:%d/hook", os.Getenv("TEST_WEBHOOK_HOST"), port)
+}
+
+func TestWebhooks(t *testing.T) {
+	client := NewClient("http://localhost:" + os.Getenv("TEST_SERVICE_PORT"))
+	dbVerifier := setupDBVerifier(t)
+	defer dbVerifier.Close()
+
+	timestamp := time.Now().UnixNano()
+	teamName := fmt.Sprintf("webhook-team-%d", timestamp)
+	authorID := fmt.Sprintf("webhook-author-%d", timestamp)
+	prID := fmt.Sprintf("webhook-pr-%d", timestamp)
+	failingPRID := fmt.Sprintf("webhook-failing-pr-%d", timestamp)
+	secret := "webhook-test-secret"
+
+	_, statusCode, err := client.AddTeam(&model.Team{
+		TeamName:          teamName,
+		ReviewersRequired: 1,
+		Members: []model.TeamMember{
+			{UserID: authorID, Username: "Webhook Author", IsActive: true},
+			{UserID: fmt.Sprintf("webhook-first-%d", timestamp), Username: "Webhook First", IsActive: true},
+			{UserID: fmt.Sprintf("webhook-second-%d", timestamp), Username: "Webhook Second", IsActive: true},
+		},
+	})
+	require.NoError(t, err, "Adding team should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Team creation should succeed")
+
+	receiver, receiverURL := startWebhookReceiver(t)
+
+	resp, statusCode, err := client.Subscribe(&model.WebhookSubscription{
+		URL:    receiverURL,
+		Secret: secret,
+		EventTypes: []string{
+			model.WebhookEventReviewersAssigned,
+			model.WebhookEventReviewerReassigned,
+			model.WebhookEventPRMerged,
+		},
+	})
+	require.NoError(t, err, "API call should not fail")
+	require.Equal(t, http.StatusCreated, statusCode, "Subscription should be created")
+	subscription := resp.(model.WebhookSubscription)
+	require.NotEmpty(t, subscription.SubscriptionID, "Subscription ID should be generated")
+	assert.Equal(t, secret, subscription.Secret, "Secret should be returned on creation")
+	t.Cleanup(func() {
+		_, _, _ = client.Unsubscribe(subscription.SubscriptionID)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// findDelivery is polled by Eventually, so it reports failures as a missing delivery.
+	findDelivery := func(eventID string) *model.WebhookDelivery {
+		resp, statusCode, err := client.ListDeliveries(url.Values{"subscription_id": {subscription.SubscriptionID}})
+		if err != nil || statusCode != http.StatusOK {
+			return nil
+		}
+		for _, delivery := range resp.([]model.WebhookDelivery) {
+			if delivery.EventID == eventID {
+				return &delivery
+			}
+		}
+		return nil
+	}
+
+	t.Run("Subscriptions are listed without secrets", func(t *testing.T) {
+		resp, statusCode, err := client.ListSubscriptions()
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Listing subscriptions should succeed")
+
+		var found *model.WebhookSubscription
+		for _, listed := range resp.([]model.WebhookSubscription) {
+			if listed.SubscriptionID == subscription.SubscriptionID {
+				found = &listed
+			}
+		}
+		require.NotNil(t, found, "Subscription should be listed")
+		assert.Equal(t, receiverURL, found.URL, "URL should match")
+		assert.ElementsMatch(t, subscription.EventTypes, found.EventTypes, "Event types should match")
+		assert.Empty(t, found.Secret, "Secret should not be listed")
+	})
+
+	t.Run("Signed event is retried until delivered", func(t *testing.T) {
+		receiver.setFailures(2)
+
+		_, statusCode, err := client.CreatePR(prID, "Webhook PR", authorID)
+		require.NoError(t, err, "Creating PR should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+		require.Eventually(t, func() bool {
+			requests := receiver.received(model.WebhookEventReviewersAssigned, prID)
+			return len(requests) == 3
+		}, 20*time.Second, 100*time.Millisecond, "Event should be delivered on the third attempt")
+
+		requests := receiver.received(model.WebhookEventReviewersAssigned, prID)
+		for i, request := range requests {
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write(request.body)
+			assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), request.header.Get(model.WebhookSignatureHeader), "Signature should match")
+			assert.Equal(t, model.WebhookEventReviewersAssigned, request.header.Get(model.WebhookEventHeader), "Event header should match")
+			assert.Equal(t, requests[0].event.EventID, request.event.EventID, "Retries should carry the same event")
+			assert.Equal(t, requests[0].header.Get(model.WebhookDeliveryHeader), request.header.Get(model.WebhookDeliveryHeader), "Retries should be the same delivery")
+			if i < 2 {
+				assert.Equal(t, http.StatusInternalServerError, request.statusCode, "First attempts should fail")
+			}
+		}
+
+		var pr model.PullRequest
+		require.NoError(t, json.Unmarshal(requests[2].event.Data, &pr), "Event data should be a PR")
+		assert.Len(t, pr.AssignedReviewers, 1, "Assigned reviewer should be sent")
+
+		require.Eventually(t, func() bool {
+			delivery := findDelivery(requests[0].event.EventID)
+			return delivery != nil && delivery.Status == model.DeliveryStatusDelivered
+		}, 5*time.Second, 100*time.Millisecond, "Delivery should be marked as delivered")
+
+		delivery := findDelivery(requests[0].event.EventID)
+		assert.Equal(t, 3, delivery.AttemptCount, "Three attempts should be counted")
+		require.Len(t, delivery.Attempts, 3, "All attempts should be recorded")
+		assert.Equal(t, http.StatusInternalServerError, delivery.Attempts[0].StatusCode, "Failed attempt should be recorded")
+		assert.NotEmpty(t, delivery.Attempts[0].Error, "Failure reason should be recorded")
+		assert.Equal(t, http.StatusOK, delivery.Attempts[2].StatusCode, "Successful attempt should be recorded")
+		assert.NotNil(t, delivery.DeliveredAt, "Delivery time should be set")
+	})
+
+	t.Run("Reassignment and merge are delivered", func(t *testing.T) {
+		dbPR, err := dbVerifier.GetPullRequest(ctx, prID)
+		require.NoError(t, err, "Getting PR from database should not fail")
+		require.Len(t, dbPR.AssignedReviewers, 1, "One reviewer should be assigned")
+		oldReviewerID := dbPR.AssignedReviewers[0].UserID
+
+		_, statusCode, err := client.ReassignPR(prID, oldReviewerID)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Reassignment should succeed")
+
+		_, statusCode, err = client.MergePR(prID)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Merge should succeed")
+
+		require.Eventually(t, func() bool {
+			return len(receiver.received(model.WebhookEventReviewerReassigned, prID)) == 1 &&
+				len(receiver.received(model.WebhookEventPRMerged, prID)) == 1
+		}, 10*time.Second, 100*time.Millisecond, "Both events should be delivered")
+
+		var reassignment model.ReviewerReassignment
+		request := receiver.received(model.WebhookEventReviewerReassigned, prID)[0]
+		require.NoError(t, json.Unmarshal(request.event.Data, &reassignment), "Event data should be a reassignment")
+		assert.Equal(t, []string{oldReviewerID}, reassignment.RemovedReviewers, "Old reviewer should be sent")
+		require.Len(t, reassignment.NewReviewers, 1, "New reviewer should be sent")
+		assert.NotEqual(t, oldReviewerID, reassignment.NewReviewers[0], "New reviewer should differ")
+		assert.Equal(t, model.AssignmentReasonReassign, reassignment.Reason, "Reason should be sent")
+
+		var merged model.PullRequest
+		request = receiver.received(model.WebhookEventPRMerged, prID)[0]
+		require.NoError(t, json.Unmarshal(request.event.Data, &merged), "Event data should be a PR")
+		assert.Equal(t, model.PRStatusMerged, merged.Status, "Merged PR should be sent")
+
+		_, statusCode, err = client.MergePR(prID)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Repeated merge should succeed")
+		time.Sleep(2 * time.Second)
+		assert.Len(t, receiver.received(model.WebhookEventPRMerged, prID), 1, "Repeated merge should not be delivered")
+	})
+
+	t.Run("Events are published from the outbox", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			count, err := dbVerifier.CountOutboxEvents(ctx, prID, false)
+			return err == nil && count == 0
+		}, 5*time.Second, 100*time.Millisecond, "All events should be published")
+
+		count, err := dbVerifier.CountOutboxEvents(ctx, prID, true)
+		require.NoError(t, err, "Counting outbox events should not fail")
+		assert.Equal(t, 3, count, "Assignment, reassignment and merge should be written once")
+
+		requests := receiver.received(model.WebhookEventPRMerged, prID)
+		require.Len(t, requests, 1, "Merge should be delivered")
+		assert.Regexp(t, `^\d+$`, requests[0].event.EventID, "Event ID should be the outbox ID")
+	})
+
+	t.Run("Failed delivery is replayed", func(t *testing.T) {
+		receiver.setFailures(-1)
+
+		_, statusCode, err := client.CreatePR(failingPRID, "Webhook Failing PR", authorID)
+		require.NoError(t, err, "Creating PR should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "PR creation should succeed")
+
+		require.Eventually(t, func() bool {
+			requests := receiver.received(model.WebhookEventReviewersAssigned, failingPRID)
+			if len(requests) == 0 {
+				return false
+			}
+			delivery := findDelivery(requests[0].event.EventID)
+			return delivery != nil && delivery.Status == model.DeliveryStatusFailed
+		}, 30*time.Second, 200*time.Millisecond, "Delivery should fail after all attempts")
+
+		eventID := receiver.received(model.WebhookEventReviewersAssigned, failingPRID)[0].event.EventID
+		failed := findDelivery(eventID)
+		assert.Len(t, failed.Attempts, failed.AttemptCount, "Every attempt should be recorded")
+		assert.Nil(t, failed.NextAttemptAt, "Failed delivery should not be retried")
+		assert.NotEmpty(t, failed.LastError, "Last error should be recorded")
+
+		resp, statusCode, err := client.ListDeliveries(url.Values{
+			"subscription_id": {subscription.SubscriptionID},
+			"status":          {model.DeliveryStatusFailed},
+		})
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Listing deliveries should succeed")
+		require.Len(t, resp.([]model.WebhookDelivery), 1, "Only the failed delivery should be listed")
+
+		receiver.setFailures(0)
+		resp, statusCode, err = client.ReplayDelivery(failed.DeliveryID)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Replay should succeed")
+		assert.Equal(t, model.DeliveryStatusPending, resp.(model.WebhookDelivery).Status, "Replayed delivery should be pending")
+
+		require.Eventually(t, func() bool {
+			delivery := findDelivery(eventID)
+			return delivery != nil && delivery.Status == model.DeliveryStatusDelivered
+		}, 10*time.Second, 100*time.Millisecond, "Replayed delivery should be delivered")
+
+		delivery := findDelivery(eventID)
+		assert.Equal(t, 1, delivery.AttemptCount, "Attempts should be counted from the replay")
+		assert.Len(t, delivery.Attempts, len(failed.Attempts)+1, "Earlier attempts should be kept")
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		for _, invalid := range []*model.WebhookSubscription{
+			{EventTypes: []string{model.WebhookEventPRMerged}},
+			{URL: "ftp://example.com/hook", EventTypes: []string{model.WebhookEventPRMerged}},
+			{URL: receiverURL},
+			{URL: receiverURL, EventTypes: []string{"pr.unknown"}},
+		} {
+			_, statusCode, err := client.Subscribe(invalid)
+			require.NoError(t, err, "API call should not fail")
+			assert.Equal(t, http.StatusBadRequest, statusCode, "%+v should be rejected", invalid)
+		}
+
+		_, statusCode, err := client.ListDeliveries(url.Values{"status": {"unknown"}})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusBadRequest, statusCode, "Unknown status should be rejected")
+
+		_, statusCode, err = client.ReplayDelivery(1 << 62)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusNotFound, statusCode, "Unknown delivery should not be found")
+	})
+
+	t.Run("Only admins manage webhooks", func(t *testing.T) {
+		resp, statusCode, err := client.IssueToken(&model.APIToken{UserID: authorID})
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusCreated, statusCode, "Issuing token should succeed")
+		authorClient := client.WithToken(resp.(model.IssuedToken).Secret)
+
+		_, statusCode, err = authorClient.Subscribe(&model.WebhookSubscription{URL: receiverURL, EventTypes: []string{model.WebhookEventPRMerged}})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Non-admin should not subscribe")
+
+		_, statusCode, err = authorClient.ListDeliveries(url.Values{"subscription_id": {subscription.SubscriptionID}})
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusForbidden, statusCode, "Non-admin should not list deliveries")
+	})
+
+	t.Run("Unsubscribe removes deliveries", func(t *testing.T) {
+		_, statusCode, err := client.Unsubscribe(subscription.SubscriptionID)
+		require.NoError(t, err, "API call should not fail")
+		require.Equal(t, http.StatusOK, statusCode, "Unsubscribing should succeed")
+
+		count, err := dbVerifier.CountWebhookDeliveries(ctx, subscription.SubscriptionID)
+		require.NoError(t, err, "Counting deliveries should not fail")
+		assert.Zero(t, count, "Deliveries should be removed")
+
+		_, statusCode, err = client.Unsubscribe(subscription.SubscriptionID)
+		require.NoError(t, err, "API call should not fail")
+		assert.Equal(t, http.StatusNotFound, statusCode, "Removed subscription should not be found")
+	})
+}